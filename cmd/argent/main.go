@@ -1,11 +1,15 @@
 package main
 
 import (
+    "flag"
     "github.com/hajimehoshi/ebiten/v2"
     "image"
     "image/color"
     "argent/pkg/game"
+    "argent/pkg/sim"
     "log"
+    "os"
+    "strings"
 )
 
 func init() {
@@ -44,13 +48,79 @@ func createIcon() image.Image {
     return icon
 }
 
+// loadMap finds a built-in map by name, or reads a map file
+func loadMap(name string) (*sim.MapFile, error) {
+    if _, err := os.Stat(name); err == nil {
+        return sim.LoadMap(name)
+    }
+    return sim.BuiltinMap(name)
+}
+
 func main() {
+    seed := flag.Int64("seed", 0, "random seed for a reproducible run (0 picks one)")
+    record := flag.String("record", "", "save a replay of the match to this file on exit")
+    replayPath := flag.String("replay", "", "play back a replay file")
+    savePath := flag.String("save-file", "argent-save.json", "file used by the in-game Save and Load buttons")
+    mapPath := flag.String("map-file", "argent-map.json", "file the map editor saves maps to")
+    loadPath := flag.String("load", "", "resume a saved match from this file")
+    towersPath := flag.String("towers", "", "override tower stats from this JSON file")
+    enemiesPath := flag.String("enemies", "", "override enemy stats from this JSON file")
+    wavesPath := flag.String("waves", "", "play the waves described in this JSON file")
+    mapName := flag.String("map", sim.DefaultMap, "built-in map ("+strings.Join(sim.BuiltinMaps(), ", ")+") or map file to play")
+    flag.Parse()
+
+    if *towersPath != "" {
+        if err := sim.LoadTowerDefs(*towersPath); err != nil {
+            log.Fatal(err)
+        }
+    }
+    if *enemiesPath != "" {
+        if err := sim.LoadEnemyDefs(*enemiesPath); err != nil {
+            log.Fatal(err)
+        }
+    }
+    if *wavesPath != "" {
+        if err := sim.LoadWaves(*wavesPath); err != nil {
+            log.Fatal(err)
+        }
+    }
+
+    layout, err := loadMap(*mapName)
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    cfg := game.Config{Seed: *seed, Map: layout, SavePath: *savePath, MapPath: *mapPath}
+    if *replayPath != "" {
+        replay, err := sim.LoadReplay(*replayPath)
+        if err != nil {
+            log.Fatal(err)
+        }
+        cfg.Replay = replay
+        if cfg.Resume, err = replay.NewWorld(); err != nil {
+            log.Fatal(err)
+        }
+    } else if *loadPath != "" {
+        world, err := sim.LoadWorld(*loadPath)
+        if err != nil {
+            log.Fatal(err)
+        }
+        cfg.Resume = world
+    }
+
     // Set window icon
     ebiten.SetWindowIcon([]image.Image{createIcon()})
     
     // Create and run game
-    g := game.NewGame()
+    g := game.NewGame(cfg)
     if err := ebiten.RunGame(g); err != nil {
         log.Fatal(err)
     }
+
+    if *record != "" {
+        if err := g.Recording().Save(*record); err != nil {
+            log.Fatal(err)
+        }
+        log.Printf("Replay saved to %s", *record)
+    }
 }
\ No newline at end of file
@@ -0,0 +1,71 @@
+package game
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"argent/pkg/sim"
+)
+
+// chainArcFrames is how long a chain lightning arc stays on screen
+const chainArcFrames = 12
+
+// chainArc is the bolt of a chained lightning shot, drawn through the
+// enemies it hit in the order it hit them
+type chainArc struct {
+	points    [][2]float64 // Screen positions of the enemies hit
+	frameLife int
+}
+
+// newChainArc captures where the enemies of a chain were when it struck
+func newChainArc(chain []*sim.Enemy, m *sim.GameMap) *chainArc {
+	arc := &chainArc{frameLife: chainArcFrames}
+	for _, enemy := range chain {
+		arc.points = append(arc.points, [2]float64{enemy.X + fieldOffsetX(m), enemy.Y + float64(uiHeight)})
+	}
+	return arc
+}
+
+// Update ages the arc and reports whether it is still showing
+func (a *chainArc) Update() bool {
+	a.frameLife--
+	return a.frameLife > 0
+}
+
+// Draw draws a jagged bolt between each enemy and the next, fading out
+func (a *chainArc) Draw(screen *ebiten.Image, fx *rand.Rand) {
+	fade := float64(a.frameLife) / chainArcFrames
+	glow := color.RGBA{180, 240, 255, uint8(90 * fade)}
+	core := color.RGBA{120, 240, 255, uint8(255 * fade)}
+
+	for i := 1; i < len(a.points); i++ {
+		fromX, fromY := a.points[i-1][0], a.points[i-1][1]
+		toX, toY := a.points[i][0], a.points[i][1]
+		length := math.Hypot(toX-fromX, toY-fromY)
+		if length == 0 {
+			continue
+		}
+		// Unit vector across the bolt, for the zigzag
+		normalX, normalY := -(toY-fromY)/length, (toX-fromX)/length
+
+		const segments = 5
+		lastX, lastY := fromX, fromY
+		for s := 1; s <= segments; s++ {
+			t := float64(s) / segments
+			x, y := fromX+(toX-fromX)*t, fromY+(toY-fromY)*t
+			if s < segments {
+				offset := (fx.Float64() - 0.5) * 10
+				x += normalX * offset
+				y += normalY * offset
+			}
+			vector.StrokeLine(screen, float32(lastX), float32(lastY), float32(x), float32(y), 5, glow, true)
+			vector.StrokeLine(screen, float32(lastX), float32(lastY), float32(x), float32(y), 1.5, core, true)
+			lastX, lastY = x, y
+		}
+		vector.DrawFilledCircle(screen, float32(toX), float32(toY), float32(3*fade+1), core, true)
+	}
+}
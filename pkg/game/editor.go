@@ -0,0 +1,386 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"argent/pkg/sim"
+)
+
+// editorTool is what a click on the map does in the map editor
+type editorTool int
+
+const (
+	toolGround editorTool = iota
+	toolRock
+	toolWater
+	toolMud
+	toolNoBuild
+	toolEntrance
+	toolExit
+	toolTower
+)
+
+// editorTools lists the tools in the order their buttons appear
+var editorTools = []struct {
+	tool    editorTool
+	name    string
+	terrain sim.TerrainType // Ground painted by the terrain tools
+}{
+	{toolGround, "Ground", sim.Empty},
+	{toolRock, "Rock", sim.Rock},
+	{toolWater, "Water", sim.Water},
+	{toolMud, "Mud", sim.Mud},
+	{toolNoBuild, "No build", sim.NoBuild},
+	{toolEntrance, "Entrance", 0},
+	{toolExit, "Exit", 0},
+	{toolTower, "Tower", 0},
+}
+
+var (
+	entranceColor = color.RGBA{0, 200, 0, 120}
+	exitColor     = color.RGBA{220, 40, 40, 120}
+	selectedColor = color.RGBA{255, 255, 255, 255}
+	problemColor  = color.RGBA{255, 90, 90, 255}
+)
+
+// mapEditor holds the front-end side of the map editor: the tool in hand
+// and the buttons of the editor's bottom bar
+type mapEditor struct {
+	tool        editorTool
+	tower       sim.TowerType // Type put up by the tower tool
+	selected    int           // Entrance or exit being moved, -1 for none
+	toolButtons []Button
+	moneyDown   Button
+	moneyUp     Button
+	livesDown   Button
+	livesUp     Button
+	problem     string // Why the map can't be played, empty when it can
+}
+
+// layoutEditorBar places the editor buttons in the bar below the map
+func (g *Game) layoutEditorBar() {
+	top := fieldBottom(g.world.Map)
+	e := &g.editor
+
+	e.toolButtons = make([]Button, 0, len(editorTools))
+	x := 20
+	for _, t := range editorTools {
+		e.toolButtons = append(e.toolButtons, Button{
+			x: x, y: top + 10, width: 100, height: 26,
+			text:  t.name,
+			color: color.RGBA{0, 160, 200, 255},
+		})
+		x += 108
+	}
+
+	small := func(x int, text string) Button {
+		return Button{x: x, y: top + 45, width: 30, height: 26, text: text, color: color.RGBA{0, 160, 200, 255}}
+	}
+	e.moneyDown, e.moneyUp = small(200, "-"), small(240, "+")
+	e.livesDown, e.livesUp = small(500, "-"), small(540, "+")
+}
+
+// openEditor switches the match to the map editor
+func (g *Game) openEditor() {
+	if err := g.world.OpenEditor(); err != nil {
+		g.showStatus(err.Error())
+		return
+	}
+	g.editor.selected = -1
+	g.inspector.tower = nil
+	g.layoutEditorBar()
+	g.checkMap()
+}
+
+// closeEditor starts a match on the edited map if it can be played
+func (g *Game) closeEditor() {
+	if err := g.world.CloseEditor(); err != nil {
+		g.showStatus(err.Error())
+		return
+	}
+	// The match starts over on the new map, so the recording does too
+	g.recording = newRecording(g.world)
+	g.showStatus("Map ready - build your defenses")
+}
+
+// saveMap writes the map being edited to the map file
+func (g *Game) saveMap() {
+	if g.editor.problem != "" {
+		g.showStatus("Fix the map before saving it")
+		return
+	}
+	if err := g.world.Layout.Save(g.config.MapPath); err != nil {
+		log.Printf("Map save failed: %v", err)
+		g.showStatus("Map save failed")
+		return
+	}
+	g.showStatus("Map saved to " + g.config.MapPath)
+}
+
+// checkMap looks for anything that keeps the edited map from being played
+func (g *Game) checkMap() {
+	g.editor.problem = ""
+	if err := g.world.MapProblem(); err != nil {
+		g.editor.problem = err.Error()
+	}
+}
+
+// updateEditor handles the mouse while the map editor is open
+func (g *Game) updateEditor() {
+	e := &g.editor
+	w := g.world
+	mouseX, mouseY := g.mouseX, g.mouseY
+	leftClick := inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft)
+	rightClick := inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight)
+
+	if leftClick {
+		switch {
+		case g.startButton.contains(mouseX, mouseY):
+			g.closeEditor()
+			return
+		case g.pauseButton.contains(mouseX, mouseY):
+			g.saveMap()
+			return
+		case e.moneyDown.contains(mouseX, mouseY):
+			g.editorResult(w.SetStartingMoney(w.Layout.StartingMoney() - 25))
+			return
+		case e.moneyUp.contains(mouseX, mouseY):
+			g.editorResult(w.SetStartingMoney(w.Layout.StartingMoney() + 25))
+			return
+		case e.livesDown.contains(mouseX, mouseY):
+			g.editorResult(w.SetStartingLives(w.Layout.StartingLives() - 1))
+			return
+		case e.livesUp.contains(mouseX, mouseY):
+			g.editorResult(w.SetStartingLives(w.Layout.StartingLives() + 1))
+			return
+		}
+
+		for i, btn := range e.toolButtons {
+			if !btn.contains(mouseX, mouseY) {
+				continue
+			}
+			tool := editorTools[i].tool
+			if tool == toolTower && e.tool == toolTower {
+				// Clicking the tower tool again picks the next tower type
+				types := w.Defs.TowerTypes()
+				for j, t := range types {
+					if t == e.tower {
+						e.tower = types[(j+1)%len(types)]
+						break
+					}
+				}
+			}
+			if tool != e.tool {
+				e.selected = -1
+			}
+			e.tool = tool
+			return
+		}
+	}
+
+	if !g.inField(mouseY) {
+		return
+	}
+	gridX, gridY := g.GetGridPosition(float64(mouseX), float64(mouseY))
+
+	switch e.tool {
+	case toolEntrance, toolExit:
+		if leftClick || rightClick {
+			g.editSpan(gridX, gridY, rightClick)
+		}
+	case toolTower:
+		if leftClick {
+			g.editorResult(w.ToggleMapTower(e.tower, gridX, gridY))
+		}
+	default:
+		// Terrain is painted while the button is held; the right button erases
+		terrain := editorTools[e.tool].terrain
+		if ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight) {
+			terrain = sim.Empty
+		} else if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+			return
+		}
+		if w.Map.Terrain[gridY][gridX] != terrain || w.Map.GetTowerAt(gridX, gridY) != nil {
+			g.editorResult(w.PaintTerrain(gridX, gridY, terrain))
+		}
+	}
+}
+
+// editSpan handles a click on the map with the entrance or exit tool. A
+// left click on an entrance or exit picks it up, and a left click anywhere
+// else along an edge moves the picked one there. A right click stretches or
+// shrinks the picked one to reach the clicked cell.
+func (g *Game) editSpan(gridX, gridY int, resize bool) {
+	e := &g.editor
+	w := g.world
+	spans, move := w.Map.Entrances, w.MoveEntrance
+	if e.tool == toolExit {
+		spans, move = w.Map.Exits, w.MoveExit
+	}
+
+	edge, pos, ok := g.edgeAt(gridX, gridY)
+	if !ok {
+		g.showStatus("Entrances and exits sit on the edge of the map")
+		return
+	}
+
+	// Pick up the one under the mouse
+	if !resize {
+		for i, span := range spans {
+			if span.Edge == edge && pos >= span.Start && pos <= span.End {
+				e.selected = i
+				return
+			}
+		}
+	}
+	if e.selected < 0 || e.selected >= len(spans) {
+		g.showStatus("Click an entrance or exit to pick it up first")
+		return
+	}
+
+	span := spans[e.selected]
+	if resize {
+		if edge != span.Edge {
+			g.showStatus("Stretch along the same edge")
+			return
+		}
+		if pos < span.Start {
+			span.Start = pos
+		} else {
+			span.End = pos
+		}
+	} else {
+		// Keep its length, sliding it back if it would run off the edge
+		length := span.End - span.Start
+		limit := w.Map.Height
+		if edge == sim.EdgeTop || edge == sim.EdgeBottom {
+			limit = w.Map.Width
+		}
+		start := min(pos, limit-1-length)
+		span = sim.Span{Edge: edge, Start: max(start, 0), End: min(start+length, limit-1)}
+	}
+	g.editorResult(move(e.selected, span))
+}
+
+// edgeAt returns the edge of the map a cell lies on under the mouse and the
+// cell's position along it. Corner cells belong to the edge the mouse is
+// closest to.
+func (g *Game) edgeAt(gridX, gridY int) (sim.Edge, int, bool) {
+	m := g.world.Map
+	cellSize := float64(m.CellSize)
+	localX := float64(g.mouseX) - fieldOffsetX(m) - float64(gridX)*cellSize
+	localY := float64(g.mouseY-uiHeight) - float64(gridY)*cellSize
+
+	// Distance from the mouse to each border the cell touches
+	best, bestDist := sim.EdgeLeft, cellSize*2
+	try := func(edge sim.Edge, onEdge bool, dist float64) {
+		if onEdge && dist < bestDist {
+			best, bestDist = edge, dist
+		}
+	}
+	try(sim.EdgeLeft, gridX == 0, localX)
+	try(sim.EdgeRight, gridX == m.Width-1, cellSize-localX)
+	try(sim.EdgeTop, gridY == 0, localY)
+	try(sim.EdgeBottom, gridY == m.Height-1, cellSize-localY)
+	if bestDist > cellSize {
+		return 0, 0, false
+	}
+
+	if best == sim.EdgeTop || best == sim.EdgeBottom {
+		return best, gridX, true
+	}
+	return best, gridY, true
+}
+
+// editorResult reports a failed edit and rechecks the map after any edit
+func (g *Game) editorResult(err error) {
+	if err != nil {
+		if !errors.Is(err, sim.ErrWrongState) {
+			log.Printf("Edit failed: %v", err)
+		}
+		g.showStatus(err.Error())
+	}
+	g.checkMap()
+}
+
+// drawEditor draws entrances and exits and the editor's bottom bar
+func (g *Game) drawEditor(screen *ebiten.Image) {
+	e := &g.editor
+	w := g.world
+	m := w.Map
+	offsetX := float32(fieldOffsetX(m))
+	cellSize := float32(m.CellSize)
+
+	// Tint entrances green and exits red, outlining the one picked up
+	drawSpans := func(spans []sim.Span, tint color.Color, tool editorTool) {
+		for i, span := range spans {
+			for _, cell := range m.SpanCells(span) {
+				vector.DrawFilledRect(screen, offsetX+float32(cell.X)*cellSize, uiHeight+float32(cell.Y)*cellSize,
+					cellSize, cellSize, tint, false)
+			}
+			label := fmt.Sprintf("%d", i+1)
+			first := m.SpanCells(span)[0]
+			DrawSmallText(screen, label, int(offsetX+float32(first.X)*cellSize)+4, uiHeight+int(float32(first.Y)*cellSize)+14, color.White)
+			if e.tool == tool && e.selected == i {
+				cells := m.SpanCells(span)
+				last := cells[len(cells)-1]
+				x0, y0 := offsetX+float32(first.X)*cellSize, uiHeight+float32(first.Y)*cellSize
+				x1, y1 := offsetX+float32(last.X+1)*cellSize, uiHeight+float32(last.Y+1)*cellSize
+				vector.StrokeRect(screen, x0, y0, x1-x0, y1-y0, 2, selectedColor, false)
+			}
+		}
+	}
+	drawSpans(m.Entrances, entranceColor, toolEntrance)
+	drawSpans(m.Exits, exitColor, toolExit)
+
+	// Outline the cell under the mouse
+	if g.inField(g.mouseY) {
+		gridX, gridY := g.GetGridPosition(float64(g.mouseX), float64(g.mouseY))
+		vector.StrokeRect(screen, offsetX+float32(gridX)*cellSize, uiHeight+float32(gridY)*cellSize,
+			cellSize, cellSize, 1, color.RGBA{200, 200, 200, 160}, false)
+	}
+
+	// Tool buttons, with the tower tool naming the tower it puts up
+	for i, btn := range e.toolButtons {
+		if editorTools[i].tool == toolTower {
+			btn.text = g.world.TowerDefFor(e.tower).Name
+		}
+		drawEditorButton(screen, btn, editorTools[i].tool == e.tool, g.mouseX, g.mouseY)
+	}
+
+	// Starting money and lives
+	top := fieldBottom(m)
+	DrawText(screen, "Money:", 20, top+64, color.White)
+	DrawText(screen, fmt.Sprintf("%d", w.Layout.StartingMoney()), 110, top+64, color.White)
+	DrawText(screen, "Lives:", 320, top+64, color.White)
+	DrawText(screen, fmt.Sprintf("%d", w.Layout.StartingLives()), 410, top+64, color.White)
+	for _, btn := range []Button{e.moneyDown, e.moneyUp, e.livesDown, e.livesUp} {
+		drawEditorButton(screen, btn, false, g.mouseX, g.mouseY)
+	}
+
+	// Show why the map can't be played, unless a message is showing
+	if e.problem != "" && g.statusTimer == 0 {
+		DrawSmallText(screen, e.problem, 20, top+90, problemColor)
+	}
+}
+
+// drawEditorButton draws one of the editor's buttons
+func drawEditorButton(screen *ebiten.Image, btn Button, active bool, mouseX, mouseY int) {
+	buttonColor := btn.color
+	if active {
+		buttonColor = color.RGBA{0, 220, 120, 255}
+	} else if btn.contains(mouseX, mouseY) {
+		buttonColor = color.RGBA{0, 210, 255, 255}
+	}
+	vector.DrawFilledRect(screen, float32(btn.x), float32(btn.y),
+		float32(btn.width), float32(btn.height), buttonColor, true)
+	textWidth := MeasureTextWidth(btn.text, false)
+	DrawText(screen, btn.text, btn.x+(btn.width-textWidth)/2, btn.y+19, color.Black)
+}
@@ -0,0 +1,33 @@
+package game
+
+import (
+	"fmt"
+
+	"argent/pkg/sim"
+)
+
+// subscribe hooks the sounds and effects of the front-end up to a world's
+// events. It has to be called again whenever the world is replaced.
+func (g *Game) subscribe(events *sim.EventBus) {
+	events.Subscribe(sim.EventTowerFired, func(sim.Event) {
+		PlayTowerShootSound()
+	})
+	events.Subscribe(sim.EventTowerDamaged, func(sim.Event) {
+		PlayAttackSound()
+	})
+	events.Subscribe(sim.EventTowerUpgraded, func(e sim.Event) {
+		g.showStatus(fmt.Sprintf("%s tower upgraded to level %d", g.world.TowerDefFor(e.Tower.Type).Name, e.Tower.Level))
+	})
+	events.Subscribe(sim.EventChainLightning, func(e sim.Event) {
+		g.chainArcs = append(g.chainArcs, newChainArc(e.Chain, g.world.Map))
+	})
+	events.Subscribe(sim.EventEnemyKilled, func(e sim.Event) {
+		enemy := e.Enemy
+		g.deathAnims = append(g.deathAnims, NewDeathAnimation(enemy.X+fieldOffsetX(g.world.Map), enemy.Y+float64(uiHeight), enemy.Size, enemySprite(enemy, g.world.EnemyDefFor(enemy.Type))))
+		PlayEnemyDeathSound()
+		delete(g.eyeFlashes, enemy.ID)
+	})
+	events.Subscribe(sim.EventEnemyLeaked, func(e sim.Event) {
+		delete(g.eyeFlashes, e.Enemy.ID)
+	})
+}
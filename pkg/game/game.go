@@ -4,64 +4,49 @@ import (
 	"fmt"
 	"image/color"
 	"log"
-	"math"
 	"math/rand"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
-)
 
-// currentGame holds the current game instance for global access
-var currentGame *Game
+	"argent/pkg/sim"
+)
 
-func GetGameMap() *GameMap {
-	if currentGame != nil {
-		return currentGame.gameMap
-	}
-	return nil
+// Config holds the options a game is started with
+type Config struct {
+	Seed     int64        // Gameplay random seed; 0 picks one from the clock
+	Replay   *sim.Replay  // Recorded match to play back instead of reading the mouse
+	Resume   *sim.World   // Saved match to continue instead of starting fresh
+	Map      *sim.MapFile // Map for a fresh match; nil plays the default map
+	SavePath string       // File used by the in-game Save and Load buttons
+	MapPath  string       // File the map editor saves to
 }
 
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
-}
-
-// GameState represents the current state of the game
-type GameState int
-
-const (
-	BuildState GameState = iota
-	PlayState
-	PausedState
-	GameOverState
-)
-
-// Game represents the main game state
+// Game is the ebiten front-end: it turns mouse input into simulation
+// actions and draws the simulation state
 type Game struct {
-	gameMap         *GameMap
-	enemies         []*Enemy
-	projectiles     []*Projectile     // Active projectiles
+	config          Config
+	world           *sim.World        // Simulation state
+	fx              *rand.Rand        // Cosmetic randomness, kept apart from the simulation
+	eyeFlashes      map[int]int       // Frames of eye flash each enemy has left, by enemy ID
+	recording       *sim.Replay       // Every action applied so far
+	replay          *sim.ReplayPlayer // Playback source, nil when playing live
 	deathAnims      []*DeathAnimation // Death animations
+	chainArcs       []*chainArc       // Chain lightning bolts still showing
 	towerButtons    []*TowerButton    // Tower selection buttons
-	score           int
-	lives           int
 	confirmingReset bool
-	money           int // Points available for tower placement
-	gameState       GameState
-	spawnTimer      int
-	spawnInterval   int
-	currentWave     int
-	enemiesInWave   int
-	enemiesSpawned  int
-	waveType        EnemyType
+	editor          mapEditor      // Map editor tools, used in the editor state
+	inspector       towerInspector // Panel for the tower clicked on the map
 	startButton     Button
 	pauseButton     Button
-	selectedTower   TowerType // Currently selected tower type
-	mouseX, mouseY  int       // Current mouse position for tower preview
+	saveButton      Button
+	loadButton      Button
+	statusText      string        // Short message shown after saving, loading or a rejected action
+	statusTimer     int           // Frames left to show the status message
+	mouseX, mouseY  int           // Current mouse position for tower preview
+	backgroundImg   *ebiten.Image // Faded backdrop behind the grid
 }
 
 // Button represents a clickable button
@@ -73,8 +58,36 @@ type Button struct {
 }
 
 // NewGame creates a new game instance
-func NewGame() *Game {
-	rand.Seed(time.Now().UnixNano())
+func NewGame(cfg Config) *Game {
+	game := newGame(cfg)
+	game.subscribe(game.world.Events)
+	return game
+}
+
+// newGame builds a game without hooking it up to its world's events, as
+// the handlers have to capture the Game that ends up being played
+func newGame(cfg Config) *Game {
+	seed := cfg.Seed
+	var replay *sim.ReplayPlayer
+	if cfg.Replay != nil {
+		// A replay only reproduces the match it was recorded with
+		seed = cfg.Replay.Seed
+		replay = sim.NewReplayPlayer(cfg.Replay)
+	}
+	if cfg.Resume != nil {
+		seed = cfg.Resume.Seed
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	log.Printf("Starting game with seed %d", seed)
+
+	world := cfg.Resume
+	if world == nil && cfg.Map != nil {
+		world = sim.NewWorldOnMap(seed, cfg.Map)
+	} else if world == nil {
+		world = sim.NewWorld(seed)
+	}
 
 	// Create start button in left section
 	startBtn := Button{
@@ -96,337 +109,341 @@ func NewGame() *Game {
 		color:  color.RGBA{200, 200, 0, 255},
 	}
 
-	// Create tower buttons at bottom of screen
-	towerButtons := make([]*TowerButton, 0)
-	btnX := 250       // Starting X position
-	btnY := 832 - 90  // 90 pixels from bottom of new height
-	btnSpacing := 100 // Space between buttons
-
-	// Create a button for each tower type
-	towerTypes := []TowerType{DartTower, BulletTower, LightningTower, FlameTower, FreezeTower, ForkTower}
-	for _, tType := range towerTypes {
-		btn := NewTowerButton(tType, btnX, btnY)
-		// Set initial selection
-		if tType == DartTower {
-			btn.selected = true
-		}
-		towerButtons = append(towerButtons, btn)
-		btnX += btnSpacing
+	// Save and load buttons sit left of the tower buttons; layoutBottomBar
+	// moves them below the map
+	saveBtn := Button{
+		x:      20,
+		width:  160,
+		height: 26,
+		text:   "Save",
+		color:  color.RGBA{0, 160, 200, 255},
+	}
+	loadBtn := Button{
+		x:      20,
+		width:  160,
+		height: 26,
+		text:   "Load",
+		color:  color.RGBA{0, 160, 200, 255},
 	}
 
 	game := &Game{
-		gameMap:        NewGameMap(),
-		enemies:        make([]*Enemy, 0),
-		projectiles:    make([]*Projectile, 0),
-		deathAnims:     make([]*DeathAnimation, 0),
-		towerButtons:   towerButtons,
-		score:          0,
-		lives:          20,
-		money:          200, // Starting points - enough for any basic tower setup
-		gameState:      BuildState,
-		spawnTimer:     0,
-		spawnInterval:  60, // Start with 1 second between spawns
-		currentWave:    0,
-		enemiesInWave:  10, // 10 enemies per wave
-		enemiesSpawned: 0,
-		waveType:       SpiderEnemy, // Start with spiders
-		startButton:    startBtn,
-		pauseButton:    pauseBtn,
-		selectedTower:  DartTower, // Default to dart tower
-	}
-
-	currentGame = game
+		config:        cfg,
+		world:         world,
+		fx:            rand.New(rand.NewSource(time.Now().UnixNano())),
+		eyeFlashes:    make(map[int]int),
+		recording:     newRecording(world),
+		replay:        replay,
+		deathAnims:    make([]*DeathAnimation, 0),
+		startButton:   startBtn,
+		pauseButton:   pauseBtn,
+		saveButton:    saveBtn,
+		loadButton:    loadBtn,
+		backgroundImg: loadBackground(),
+	}
+	game.layoutBottomBar()
+	game.syncButtons()
+
 	return game
 }
 
-// Update updates the game state
+// Update reads player input and advances the simulation by one tick
 func (g *Game) Update() error {
 	// Update mouse position
 	g.mouseX, g.mouseY = ebiten.CursorPosition()
 
 	// Handle mouse position for button hover
-	mouseX, mouseY := ebiten.CursorPosition()
-	g.startButton.hovered = g.startButton.contains(mouseX, mouseY)
-	g.pauseButton.hovered = g.pauseButton.contains(mouseX, mouseY)
+	g.startButton.hovered = g.startButton.contains(g.mouseX, g.mouseY)
+	g.pauseButton.hovered = g.pauseButton.contains(g.mouseX, g.mouseY)
+	g.saveButton.hovered = g.saveButton.contains(g.mouseX, g.mouseY)
+	g.loadButton.hovered = g.loadButton.contains(g.mouseX, g.mouseY)
 
-	// Handle tower selection clicks
-	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-		// Check tower buttons first
-		for _, btn := range g.towerButtons {
-			if btn.Contains(mouseX, mouseY) && g.money >= btn.cost {
-				g.selectedTower = btn.tower
-				// Update selected state for all buttons
-				for _, otherBtn := range g.towerButtons {
-					otherBtn.selected = (otherBtn.tower == btn.tower)
-				}
-				return nil
-			}
+	if g.statusTimer > 0 {
+		g.statusTimer--
+	}
+
+	// The game over screen only offers a fresh start
+	if g.world.State == sim.GameOverState {
+		screenW, screenH := g.Layout(0, 0)
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) &&
+			overGameOverButton(screenW, screenH, g.mouseX, g.mouseY) {
+			// Replace this game with a completely new one, played live
+			cfg := g.config
+			cfg.Replay = nil
+			cfg.Resume = nil
+			cfg.Map = g.world.Layout // Play again on the same map
+			*g = *newGame(cfg)
+			g.subscribe(g.world.Events)
 		}
+		return nil
+	}
 
-		// Handle other button clicks
-		if g.startButton.contains(mouseX, mouseY) {
-			if g.gameState == BuildState {
-				g.gameState = PlayState
-				g.startButton.text = "Reset"
-				g.confirmingReset = false
-			} else if g.gameState == PlayState || g.gameState == PausedState {
-				if g.confirmingReset {
-					// Actually reset the game
-					g.gameState = BuildState
-					g.enemies = make([]*Enemy, 0)
-					g.projectiles = make([]*Projectile, 0)
-					g.deathAnims = make([]*DeathAnimation, 0)
-					g.lives = 20
-					g.money = 200 // Reset to initial money amount
-					g.currentWave = 0
-					g.enemiesInWave = 10
-					g.enemiesSpawned = 0
-					// Add this check before setting wave type
-					if g.waveType == BlobEnemy {
-						g.waveType = SpiderEnemy // Reset to normal enemy type if resetting during boss wave
-					} else {
-						g.waveType = SpiderEnemy // Normal reset
-					}
-					g.spawnInterval = 60
-					g.startButton.text = "Begin!" // Reset to initial text
-					g.pauseButton.text = "Pause"  // Reset pause button text
-					g.confirmingReset = false
-					g.score = 0
-					// Clear all towers from the map
-					g.gameMap = NewGameMap()
-					// Reset selected tower to default
-					g.selectedTower = DartTower
-					// Reset tower button selection
-					for _, btn := range g.towerButtons {
-						btn.selected = (btn.tower == DartTower)
-					}
-				} else {
-					// Ask for confirmation
-					g.confirmingReset = true
-					g.startButton.text = "Sure?"
-				}
-			}
-			return nil
+	// The map editor changes the map directly rather than through actions
+	if g.world.State == sim.EditorState {
+		g.updateEditor()
+		g.syncButtons()
+		return nil
+	}
+
+	// Take actions from the replay while it lasts, then from the mouse
+	var actions []sim.Action
+	if g.replay != nil && !g.replay.Done() {
+		actions = g.replay.Actions(g.world.Frame)
+	} else {
+		actions = g.readInput()
+	}
+	g.recording.Record(g.world.Frame, actions)
+
+	for _, action := range actions {
+		if action.Kind == sim.ActionReset {
+			g.deathAnims = make([]*DeathAnimation, 0)
+			g.chainArcs = nil
+			g.eyeFlashes = make(map[int]int) // Enemy IDs start over
+			g.confirmingReset = false
 		}
+	}
 
-		if g.pauseButton.contains(mouseX, mouseY) {
-			if g.gameState == PlayState {
-				g.gameState = PausedState
-				g.pauseButton.text = "Continue"
-				g.confirmingReset = false // Cancel reset confirmation when pausing
-			} else if g.gameState == PausedState {
-				g.gameState = PlayState
-				g.pauseButton.text = "Pause"
-				g.confirmingReset = false // Cancel reset confirmation when unpausing
+	result := g.world.Tick(actions)
+	g.syncButtons()
+	g.syncInspector()
+
+	// Tell the player why a click did nothing
+	for _, err := range result.Rejected {
+		log.Printf("Action failed: %v", err)
+		g.showStatus(err.Error())
+	}
+
+	// Update death animations and lightning arcs only while the wave is running
+	if g.world.State == sim.PlayState {
+		remainingAnims := make([]*DeathAnimation, 0)
+		for _, anim := range g.deathAnims {
+			if anim.Update() {
+				remainingAnims = append(remainingAnims, anim)
 			}
-			return nil
 		}
-	}
+		g.deathAnims = remainingAnims
 
-	// Handle tower placement
-	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-		if mouseY > g.gameMap.uiHeight { // Don't place towers in the UI area
-			gridX, gridY := g.gameMap.GetGridPosition(float64(mouseX), float64(mouseY))
-			if err := g.tryPlaceTower(gridX, gridY); err != nil {
-				log.Printf("Tower placement failed: %v", err)
+		remainingArcs := make([]*chainArc, 0, len(g.chainArcs))
+		for _, arc := range g.chainArcs {
+			if arc.Update() {
+				remainingArcs = append(remainingArcs, arc)
 			}
 		}
+		g.chainArcs = remainingArcs
 	}
 
-	// Handle tower removal
-	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
-		if mouseY > g.gameMap.uiHeight { // Don't remove towers in the UI area
-			gridX, gridY := g.gameMap.GetGridPosition(float64(mouseX), float64(mouseY))
-			// Get the tower before removing it to calculate refund
-			if tower := g.gameMap.GetTowerAt(gridX, gridY); tower != nil {
-				// Calculate refund based on tower type and remaining health
-				var baseRefund int
-				switch tower.towerType {
-				case DartTower:
-					baseRefund = 10
-				case BulletTower:
-					baseRefund = 25
-				case LightningTower:
-					baseRefund = 40
-				case FlameTower:
-					baseRefund = 60
-				case FreezeTower:
-					baseRefund = 75
-				case ForkTower:
-					baseRefund = 150
-				}
+	return nil
+}
 
-				// Calculate actual refund based on remaining health percentage
-				healthPercent := tower.health / tower.maxHealth
-				actualRefund := int(float64(baseRefund) * healthPercent)
+// Recording returns the replay of the match in progress
+func (g *Game) Recording() *sim.Replay {
+	return g.recording
+}
 
-				g.money += actualRefund
-				g.gameMap.RemoveTower(gridX, gridY)
-			}
+// newRecording starts recording a world, from its saved state if it is
+// already under way
+func newRecording(w *sim.World) *sim.Replay {
+	if w.Frame == 0 {
+		return sim.NewReplay(w.Seed, w.Layout)
+	}
+	recording, err := sim.NewReplayFrom(w)
+	if err != nil {
+		log.Printf("Recording unavailable: %v", err)
+		return sim.NewReplay(w.Seed, w.Layout)
+	}
+	return recording
+}
+
+// saveGame writes the match in progress to the save file
+func (g *Game) saveGame() {
+	if err := sim.SaveWorld(g.world, g.config.SavePath); err != nil {
+		log.Printf("Save failed: %v", err)
+		g.showStatus("Save failed")
+		return
+	}
+	g.showStatus("Game saved")
+}
+
+// loadGame replaces the match in progress with the one in the save file
+func (g *Game) loadGame() {
+	world, err := sim.LoadWorld(g.config.SavePath)
+	if err != nil {
+		log.Printf("Load failed: %v", err)
+		g.showStatus("Load failed")
+		return
+	}
+
+	g.world = world
+	g.subscribe(world.Events)
+	g.replay = nil
+	g.recording = newRecording(world)
+	g.deathAnims = make([]*DeathAnimation, 0)
+	g.chainArcs = nil
+	g.eyeFlashes = make(map[int]int)
+	g.confirmingReset = false
+	g.layoutBottomBar() // The saved match may be on a map of another size
+	g.syncButtons()
+	g.showStatus("Game loaded")
+}
+
+// layoutBottomBar places the save, load and tower buttons in the bar below
+// the map
+func (g *Game) layoutBottomBar() {
+	top := fieldBottom(g.world.Map)
+	g.saveButton.y = top + 13
+	g.loadButton.y = top + 45
+
+	// Create a button for each tower type
+	g.towerButtons = make([]*TowerButton, 0)
+	btnX := 250       // Starting X position
+	btnSpacing := 100 // Space between buttons
+	for _, tType := range g.world.Defs.TowerTypes() {
+		btn := NewTowerButton(g.world.TowerDefFor(tType), btnX, top+10)
+		g.towerButtons = append(g.towerButtons, btn)
+		btnX += btnSpacing
+	}
+
+	// So do the tower inspector and the map editor
+	g.layoutInspector()
+	g.layoutEditorBar()
+	g.editor.selected = -1
+	if g.world.State == sim.EditorState {
+		g.checkMap()
+	}
+}
+
+// showStatus displays a short message in the bottom bar
+func (g *Game) showStatus(text string) {
+	g.statusText = text
+	g.statusTimer = 120 // 2 seconds
+}
+
+// syncButtons updates button labels and highlights from the simulation state
+func (g *Game) syncButtons() {
+	switch g.world.State {
+	case sim.BuildState:
+		g.startButton.text = "Begin!"
+	case sim.EditorState:
+		g.startButton.text = "Done"
+	default:
+		if g.confirmingReset {
+			g.startButton.text = "Sure?"
+		} else {
+			g.startButton.text = "Reset"
 		}
 	}
 
-	// Handle game state logic
-	if g.gameState == BuildState || g.gameState == PausedState {
+	switch g.world.State {
+	case sim.BuildState:
+		g.pauseButton.text = "Edit Map"
+	case sim.EditorState:
+		g.pauseButton.text = "Save Map"
+	case sim.PausedState:
+		g.pauseButton.text = "Continue"
+	default:
+		g.pauseButton.text = "Pause"
 	}
 
-	// Update game logic only in play state
-	if g.gameState == PlayState {
-		// Update enemies
-		remainingEnemies := make([]*Enemy, 0, len(g.enemies))
-		for _, enemy := range g.enemies {
-			if enemy == nil {
-				continue
-			}
+	for _, btn := range g.towerButtons {
+		btn.selected = (btn.tower == g.world.SelectedTower)
+	}
+}
 
-			reached := enemy.Update(g.gameMap)
-			if reached {
-				g.lives--
-				if g.lives <= 0 {
-					g.gameState = GameOverState
-				}
-			} else if enemy.health <= 0 {
-				// Enemy was killed, create death animation with enemy sprite
-				g.deathAnims = append(g.deathAnims, NewDeathAnimation(enemy.x, enemy.y, enemy.size, enemy.sprite))
-
-				// death sound
-				PlayEnemyDeathSound()
-
-				// Award points - extra for boss
-				reward := 2 * enemy.level // Base: 2 points per level
-				if enemy.enemyType == BlobEnemy {
-					reward = reward * 5 // 5x reward for boss
-					g.score += 1000     // Bonus score for boss kill
+// readInput turns this frame's mouse clicks into simulation actions
+func (g *Game) readInput() []sim.Action {
+	var actions []sim.Action
+	mouseX, mouseY := g.mouseX, g.mouseY
+	state := g.world.State
+
+	// Handle tower selection clicks
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		// The inspector covers the tower buttons while it is open
+		if g.inspector.tower != nil {
+			if inspectorActions, ok := g.inspectorInput(mouseX, mouseY); ok {
+				return inspectorActions
+			}
+		} else {
+			for _, btn := range g.towerButtons {
+				if btn.Contains(mouseX, mouseY) && g.world.Money >= btn.cost {
+					return append(actions, sim.Action{Kind: sim.ActionSelectTower, Tower: btn.tower})
 				}
-				g.money += reward
-			} else {
-				remainingEnemies = append(remainingEnemies, enemy)
 			}
 		}
-		g.enemies = remainingEnemies
 
-		// Update death animations
-		remainingAnims := make([]*DeathAnimation, 0)
-		for _, anim := range g.deathAnims {
-			if anim.Update() {
-				remainingAnims = append(remainingAnims, anim)
-			}
+		// Saving and loading happen outside the simulation
+		if g.saveButton.contains(mouseX, mouseY) {
+			g.saveGame()
+			return actions
 		}
-		g.deathAnims = remainingAnims
-
-		// Update towers and generate projectiles
-		for _, tower := range g.gameMap.towers {
-			newProjectiles := tower.Update(g.enemies)
-			if newProjectiles != nil {
-				g.projectiles = append(g.projectiles, newProjectiles...)
-			}
+		if g.loadButton.contains(mouseX, mouseY) {
+			g.loadGame()
+			return actions
 		}
 
-		// Update projectiles and check for hits
-		remainingProjectiles := make([]*Projectile, 0, len(g.projectiles))
-		for _, proj := range g.projectiles {
-			hit := proj.Update()
-			if !hit {
-				// Keep projectile if it hasn't hit
-				remainingProjectiles = append(remainingProjectiles, proj)
-			} else {
-				// Check for collision with enemies
-				for _, enemy := range g.enemies {
-					if enemy == nil {
-						continue
-					}
-
-					// Simple collision check
-					ex, ey := enemy.x, enemy.y
-					px, py := proj.GetPosition()
-					dx := ex - px
-					dy := ey - py
-					dist := math.Sqrt(dx*dx + dy*dy)
-
-					if dist < enemy.size/2 { // If within enemy radius
-						if proj.GetProjectileType() == FreezeProjectile {
-							if enemy.frozenTimer <= 0 { // Only freeze if not already frozen
-								enemy.frozenTimer = 60 // Freeze for 1 second (60 frames)
-							}
-						} else {
-							enemy.health -= proj.GetDamage()
-						}
-						break // Exit after first hit
-					}
+		// Handle other button clicks
+		if g.startButton.contains(mouseX, mouseY) {
+			if state == sim.BuildState {
+				actions = append(actions, sim.Action{Kind: sim.ActionBegin})
+				g.confirmingReset = false
+			} else if state == sim.PlayState || state == sim.PausedState {
+				if g.confirmingReset {
+					// Actually reset the game
+					actions = append(actions, sim.Action{Kind: sim.ActionReset})
+				} else {
+					// Ask for confirmation
+					g.confirmingReset = true
 				}
 			}
+			return actions
 		}
-		g.projectiles = remainingProjectiles
-
-		// Handle wave spawning
-		if g.enemiesSpawned < g.enemiesInWave {
-			g.spawnTimer++
-			if g.spawnTimer >= g.spawnInterval {
-				g.spawnTimer = 0
-				// Random spawn intervals between 1.5-3 seconds (90-180 frames)
-				g.spawnInterval = 90 + rand.Intn(90)
-
-				// After level 5, sometimes spawn a different enemy type
-				spawnType := g.waveType
-				if g.currentWave >= 4 && g.enemiesSpawned > 0 { // Start at wave 5 (index 4)
-					if rand.Float64() < 0.05 { // 5% chance for different enemy
-						// Create list of enemy types excluding current wave type
-						availableTypes := []EnemyType{}
-						for _, t := range []EnemyType{SpiderEnemy, SnakeEnemy, HawkEnemy, GhoulEnemy} {
-							if t != g.waveType {
-								availableTypes = append(availableTypes, t)
-							}
-						}
-						if len(availableTypes) > 0 {
-							spawnType = availableTypes[rand.Intn(len(availableTypes))]
-						}
-					}
-				}
 
-				// Spawn new enemy with current wave's colors
-				entranceStart, entranceEnd, _ := g.gameMap.GetEntranceArea()
-				randomY := entranceStart + rand.Intn(entranceEnd-entranceStart+1)
-				newEnemy := NewEnemyWithColor(randomY, g.gameMap.cellSize, g.gameMap.uiHeight,
-					spawnType, g.currentWave+1, g.waveType)
-				if newEnemy != nil {
-					g.enemies = append(g.enemies, newEnemy)
-					g.enemiesSpawned++
-				}
+		if g.pauseButton.contains(mouseX, mouseY) {
+			if state == sim.BuildState {
+				// The editor works on the map directly, outside of actions
+				g.openEditor()
+			} else if state == sim.PlayState {
+				actions = append(actions, sim.Action{Kind: sim.ActionPause})
+				g.confirmingReset = false // Cancel reset confirmation when pausing
+			} else if state == sim.PausedState {
+				actions = append(actions, sim.Action{Kind: sim.ActionResume})
+				g.confirmingReset = false // Cancel reset confirmation when unpausing
 			}
+			return actions
+		}
+	}
 
-		} else if len(g.enemies) == 0 {
-			// Wave completed
-			g.currentWave++
-			g.enemiesSpawned = 0
-
-			if IsBossWave(g.currentWave) {
-				// Boss wave - spawn a single powerful blob enemy
-				g.waveType = BlobEnemy
-				g.enemiesInWave = 1 // Only one boss
+	// Handle tower placement, or inspection when a tower is already there
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		if g.inField(mouseY) { // Don't place towers in the UI area
+			gridX, gridY := g.GetGridPosition(float64(mouseX), float64(mouseY))
+			if tower := g.world.Map.GetTowerAt(gridX, gridY); tower != nil {
+				g.inspector.tower = tower
 			} else {
-				// Normal wave - cycle through enemy types
-				switch g.waveType {
-				case SpiderEnemy:
-					g.waveType = SnakeEnemy
-				case SnakeEnemy:
-					g.waveType = HawkEnemy
-				case HawkEnemy:
-					g.waveType = GhoulEnemy
-				case GhoulEnemy:
-					g.waveType = SpiderEnemy
-				case BlobEnemy:
-					g.waveType = SpiderEnemy // Reset to normal enemy type if resetting during boss wave
-				}
-				// Regular wave size calculation
-				g.enemiesInWave = 10 + g.currentWave*2
+				g.inspector.tower = nil
+				actions = append(actions, sim.Action{Kind: sim.ActionPlaceTower, X: gridX, Y: gridY})
 			}
+		}
+	}
+
+	// Close the inspector; towers are only sold from its Sell button
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) || inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.inspector.tower = nil
+	}
 
-			// Keep spawn interval adjustment
-			g.spawnInterval = max(60, 120-g.currentWave*10) // Minimum 1 second between spawns
+	// Handle tower upgrades on the tower under the mouse
+	if inpututil.IsKeyJustPressed(ebiten.KeyU) {
+		if g.inField(mouseY) {
+			gridX, gridY := g.GetGridPosition(float64(mouseX), float64(mouseY))
+			actions = append(actions, sim.Action{Kind: sim.ActionUpgradeTower, X: gridX, Y: gridY})
 		}
 	}
 
-	return nil
+	return actions
+}
+
+// inField reports whether a screen row lies on the playing field rather
+// than the top or bottom bar
+func (g *Game) inField(mouseY int) bool {
+	return mouseY > uiHeight && mouseY < fieldBottom(g.world.Map)
 }
 
 // Draw draws the game screen
@@ -435,14 +452,21 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		return
 	}
 
+	w := g.world
+
 	// Draw map
-	g.gameMap.Draw(screen)
+	g.drawMap(screen)
+
+	// Show the way enemies will go while the maze is being built
+	if w.State == sim.BuildState {
+		g.drawPathPreview(screen)
+	}
 
 	// Draw enemies if not in build state
-	if g.gameState != BuildState {
-		for _, enemy := range g.enemies {
+	if w.State != sim.BuildState {
+		for _, enemy := range w.Enemies {
 			if enemy != nil {
-				enemy.Draw(screen)
+				drawEnemy(screen, enemy, w.EnemyDefFor(enemy.Type), w.Map, g.eyeFlash(enemy), g.fx)
 			}
 		}
 
@@ -454,11 +478,16 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		}
 
 		// Draw projectiles
-		for _, proj := range g.projectiles {
+		for _, proj := range w.Projectiles {
 			if proj != nil {
-				proj.Draw(screen)
+				drawProjectile(screen, proj, w.Map, g.fx)
 			}
 		}
+
+		// Draw chain lightning through the enemies it hit
+		for _, arc := range g.chainArcs {
+			arc.Draw(screen, g.fx)
+		}
 	}
 
 	// LEFT SECTION (0-320px) - Buttons and game state
@@ -477,22 +506,20 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		true,
 	)
 
-	// Draw pause button if game has started
-	if g.gameState != BuildState {
-		buttonColor = g.pauseButton.color
-		if g.pauseButton.hovered {
-			buttonColor = color.RGBA{255, 255, 0, 255}
-		}
-		vector.DrawFilledRect(
-			screen,
-			float32(g.pauseButton.x),
-			float32(g.pauseButton.y),
-			float32(g.pauseButton.width),
-			float32(g.pauseButton.height),
-			buttonColor,
-			true,
-		)
+	// Draw pause button, which opens the map editor before the game starts
+	buttonColor = g.pauseButton.color
+	if g.pauseButton.hovered {
+		buttonColor = color.RGBA{255, 255, 0, 255}
 	}
+	vector.DrawFilledRect(
+		screen,
+		float32(g.pauseButton.x),
+		float32(g.pauseButton.y),
+		float32(g.pauseButton.width),
+		float32(g.pauseButton.height),
+		buttonColor,
+		true,
+	)
 
 	// Draw button texts centered
 	textWidth := MeasureTextWidth(g.startButton.text, false)
@@ -501,24 +528,27 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		g.startButton.x+(g.startButton.width-textWidth)/2,
 		g.startButton.y+20, color.Black)
 
-	if g.gameState != BuildState {
-		textWidth = MeasureTextWidth(g.pauseButton.text, false)
-		DrawText(screen, g.pauseButton.text,
-			g.pauseButton.x+(g.pauseButton.width-textWidth)/2,
-			g.pauseButton.y+20, color.Black)
-	}
+	textWidth = MeasureTextWidth(g.pauseButton.text, false)
+	DrawText(screen, g.pauseButton.text,
+		g.pauseButton.x+(g.pauseButton.width-textWidth)/2,
+		g.pauseButton.y+20, color.Black)
 
 	// Game state text well below buttons using small font
 	stateText := ""
-	switch g.gameState {
-	case BuildState:
+	switch w.State {
+	case sim.BuildState:
 		stateText = "Building Mode"
-	case PlayState:
+	case sim.PlayState:
 		stateText = "Wave in Progress"
-	case PausedState:
+	case sim.PausedState:
 		stateText = "Game Paused"
-	case GameOverState:
+	case sim.GameOverState:
 		stateText = "Game Over"
+	case sim.EditorState:
+		stateText = "Editing Map"
+	}
+	if g.replay != nil && !g.replay.Done() {
+		stateText += " (Replay)"
 	}
 	DrawSmallText(screen, stateText, 20, 58, color.White) // Even lower and smaller font
 
@@ -527,8 +557,8 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	DrawText(screen, "Lives:", 750, 40, color.White)
 
 	// Right-justify the numbers at x=900
-	moneyText := fmt.Sprintf("%d", g.money)
-	livesText := fmt.Sprintf("%d", g.lives)
+	moneyText := fmt.Sprintf("%d", w.Money)
+	livesText := fmt.Sprintf("%d", w.Lives)
 	moneyWidth := len(moneyText) * 12 // Approximate width for right justification
 	livesWidth := len(livesText) * 12 // Approximate width for right justification
 
@@ -536,25 +566,13 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	DrawText(screen, livesText, 900-livesWidth, 40, color.White) // Lives value
 
 	// MIDDLE SECTION (320-640px) - Wave Information
-	if g.gameState != BuildState {
-		waveTypeText := ""
-		switch g.waveType {
-		case SpiderEnemy:
-			waveTypeText = "Spiders"
-		case SnakeEnemy:
-			waveTypeText = "Snakes"
-		case HawkEnemy:
-			waveTypeText = "Hawks"
-		case GhoulEnemy:
-			waveTypeText = "Ghouls"
-		case BlobEnemy:
-			waveTypeText = "BOSS"
-		}
-		waveText := fmt.Sprintf("Wave %d", g.currentWave+1)
-		enemyInfo := fmt.Sprintf("%s: %d/%d", waveTypeText, g.enemiesSpawned, g.enemiesInWave)
+	if w.State != sim.BuildState && w.State != sim.EditorState {
+		waveTypeText := w.Wave.Title(w.Defs)
+		waveText := fmt.Sprintf("Wave %d", w.CurrentWave+1)
+		enemyInfo := fmt.Sprintf("%s: %d/%d", waveTypeText, w.EnemiesSpawned, w.EnemiesInWave)
 
 		// Center wave info
-		if IsBossWave(g.currentWave+1) && len(g.enemies) == 0 {
+		if w.NextWaveIsBoss() && len(w.Enemies) == 0 {
 			// Draw warning text in red when next wave will be boss
 			warningText := "! BOSS INCOMING !"
 			warningWidth := MeasureTextWidth(warningText, false)
@@ -567,73 +585,59 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		DrawText(screen, enemyInfo, 400, 40, color.White) // Enemy info below
 	}
 
-	// Draw tower selection buttons
-	for _, btn := range g.towerButtons {
-		btn.Draw(screen, g.money >= btn.cost)
+	if g.statusTimer > 0 {
+		DrawSmallText(screen, g.statusText, 20, fieldBottom(w.Map)+90, color.White)
+	}
+
+	// The map editor replaces the bottom bar with its own tools
+	if w.State == sim.EditorState {
+		g.drawEditor(screen)
+		return
+	}
+
+	// Draw save and load buttons
+	for _, btn := range []Button{g.saveButton, g.loadButton} {
+		buttonColor = btn.color
+		if btn.hovered {
+			buttonColor = color.RGBA{0, 210, 255, 255}
+		}
+		vector.DrawFilledRect(screen, float32(btn.x), float32(btn.y),
+			float32(btn.width), float32(btn.height), buttonColor, true)
+		textWidth = MeasureTextWidth(btn.text, false)
+		DrawText(screen, btn.text, btn.x+(btn.width-textWidth)/2, btn.y+19, color.Black)
+	}
+
+	// Draw the inspected tower's panel, or the tower selection buttons
+	if g.inspector.tower != nil {
+		g.drawInspector(screen)
+	} else {
+		for _, btn := range g.towerButtons {
+			btn.Draw(screen, w.Money >= btn.cost)
+		}
 	}
 
 	// Draw tower range preview during build or pause states
-	if g.gameState == BuildState || g.gameState == PausedState {
+	if w.State == sim.BuildState || w.State == sim.PausedState {
 		g.drawTowerRangePreview(screen)
 	}
 
 	// Show range for clicked tower
-	if g.mouseY > g.gameMap.uiHeight {
-		gridX, gridY := g.gameMap.GetGridPosition(float64(g.mouseX), float64(g.mouseY))
-		if tower := g.gameMap.GetTowerAt(gridX, gridY); tower != nil {
-			tower.Draw(screen, true) // Pass true to show range
+	if g.mouseY > uiHeight {
+		gridX, gridY := g.GetGridPosition(float64(g.mouseX), float64(g.mouseY))
+		if tower := w.Map.GetTowerAt(gridX, gridY); tower != nil {
+			drawTower(screen, tower, w.TowerDefFor(tower.Type), w.Map, true) // Pass true to show range
 		}
 	}
 
 	// Draw game over screen if dead
-	if g.gameState == GameOverState {
-		if clickHandled := drawGameOver(screen); clickHandled {
-			return // Skip processing other input if game over screen handled it
-		}
+	if w.State == sim.GameOverState {
+		drawGameOver(screen, g.mouseX, g.mouseY, g.world.Victory)
 	}
 }
 
 // Layout returns the game's logical screen size
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return 1024, 832 // 12 rows * 56px + 60px UI + 100px tower selection = 832px
-}
-
-// PlaceTower attempts to place a tower and returns an error if it fails
-func (g *Game) tryPlaceTower(x, y int) error {
-	// Calculate tower cost based on type/level
-	var towerCost int
-	switch g.selectedTower {
-	case DartTower:
-		towerCost = 10 // Basic tower
-	case BulletTower:
-		towerCost = 25 // Better range and speed
-	case LightningTower:
-		towerCost = 40 // High damage
-	case FlameTower:
-		towerCost = 60 // Area damage
-	case FreezeTower:
-		towerCost = 75 // Most expensive basic tower
-	case ForkTower:
-		towerCost = 150 // Electric fork tower with large range
-	}
-
-	// Check if we have enough points
-	if g.money < towerCost {
-		return fmt.Errorf("not enough points: need %d, have %d", towerCost, g.money)
-	}
-
-	if g.gameMap.PlaceTower(x, y) {
-		// Tower was placed successfully, deduct points
-		g.money -= towerCost
-		// Force all enemies to recalculate their paths
-		for _, enemy := range g.enemies {
-			if enemy != nil {
-				enemy.InvalidatePath()
-			}
-		}
-		return nil
-	}
-	return fmt.Errorf("cannot place tower at position %d,%d", x, y)
+	return screenSize(g.world.Map) // Rows of cells + 60px UI + 100px tower selection
 }
 
 // Button.contains checks if a point is inside the button
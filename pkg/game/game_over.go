@@ -6,12 +6,26 @@ import (
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
-// drawGameOver renders the game over screen with darkened background and skull
-func drawGameOver(screen *ebiten.Image) bool {
+// gameOverButtonRect returns the position and size of the "New Game?" button
+func gameOverButtonRect(w, h int) (int, int, int, int) {
+	buttonWidth := 240
+	buttonHeight := 50
+	return (w - buttonWidth) / 2, h - buttonHeight - 60, buttonWidth, buttonHeight
+}
+
+// overGameOverButton reports whether a point is on the "New Game?" button
+func overGameOverButton(w, h, x, y int) bool {
+	buttonX, buttonY, buttonWidth, buttonHeight := gameOverButtonRect(w, h)
+	return x >= buttonX && x < buttonX+buttonWidth &&
+		y >= buttonY && y < buttonY+buttonHeight
+}
+
+// drawGameOver renders the game over screen with darkened background and
+// skull, with a victory title if the player survived every wave
+func drawGameOver(screen *ebiten.Image, mouseX, mouseY int, victory bool) {
 	w := screen.Bounds().Dx()
 	h := screen.Bounds().Dy()
 
@@ -26,6 +40,9 @@ func drawGameOver(screen *ebiten.Image) bool {
 
 	// Draw "YOU ARE DEAD" text
 	text := "YOU ARE DEAD"
+	if victory {
+		text = "VICTORY"
+	}
 	textWidth := MeasureTextWidth(text, true) // Using large font
 	x := (w - textWidth) / 2
 	y := h/2 - 100 // Higher up to make room for skull and button
@@ -86,15 +103,10 @@ func drawGameOver(screen *ebiten.Image) bool {
 	}
 
 	// Draw "New Game?" button at bottom with pulsing effect
-	buttonWidth := 240
-	buttonHeight := 50
-	buttonX := (w - buttonWidth) / 2
-	buttonY := h - buttonHeight - 60
+	buttonX, buttonY, buttonWidth, buttonHeight := gameOverButtonRect(w, h)
 
 	// Check if mouse is over button
-	mouseX, mouseY := ebiten.CursorPosition()
-	hovered := mouseX >= buttonX && mouseX < buttonX+buttonWidth &&
-		mouseY >= buttonY && mouseY < buttonY+buttonHeight
+	hovered := overGameOverButton(w, h, mouseX, mouseY)
 
 	// Draw button glow
 	glowColor := color.RGBA{
@@ -135,14 +147,4 @@ func drawGameOver(screen *ebiten.Image) bool {
 		buttonX+(buttonWidth-buttonTextW)/2,
 		buttonY+buttonHeight/2+5,
 		textColor)
-
-	// Handle button click
-	if hovered && inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-		// Create a completely new game instance and replace the current one
-		newGame := NewGame()
-		*currentGame = *newGame
-		return true // indicate we handled the click
-	}
-	return false // no click was handled
-
 }
@@ -0,0 +1,156 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"argent/pkg/sim"
+)
+
+var (
+	inspectedColor = color.RGBA{255, 215, 0, 255} // Outline of the tower being inspected
+	statLabelColor = color.RGBA{170, 170, 170, 255}
+	sellColor      = color.RGBA{220, 90, 60, 255}
+	disabledColor  = color.RGBA{90, 90, 90, 255}
+)
+
+// towerInspector holds the panel that replaces the tower buttons while a
+// tower on the map is selected
+type towerInspector struct {
+	tower         *sim.Tower // Tower being inspected, nil when the panel is closed
+	targetButton  Button
+	upgradeButton Button
+	sellButton    Button
+}
+
+// inspectorStat is one labelled line of the inspector panel
+type inspectorStat struct {
+	label, value string
+}
+
+// layoutInspector places the inspector's buttons in the bar below the map
+func (g *Game) layoutInspector() {
+	top := fieldBottom(g.world.Map)
+	i := &g.inspector
+	i.targetButton = Button{x: 770, y: top + 8, width: 200, height: 22, color: color.RGBA{0, 160, 200, 255}}
+	i.upgradeButton = Button{x: 770, y: top + 36, width: 200, height: 22, color: color.RGBA{0, 160, 200, 255}}
+	i.sellButton = Button{x: 770, y: top + 64, width: 200, height: 22, color: sellColor}
+	i.tower = nil
+}
+
+// syncInspector closes the panel once its tower has been sold or destroyed
+func (g *Game) syncInspector() {
+	t := g.inspector.tower
+	if t != nil && g.world.Map.GetTowerAt(t.Position.X, t.Position.Y) != t {
+		g.inspector.tower = nil
+	}
+}
+
+// inspectorInput turns a click on the panel's buttons into an action on
+// the inspected tower. It reports whether the click landed on a button.
+func (g *Game) inspectorInput(mouseX, mouseY int) ([]sim.Action, bool) {
+	i := &g.inspector
+	if i.tower == nil {
+		return nil, false
+	}
+	pos := i.tower.Position
+	switch {
+	case i.targetButton.contains(mouseX, mouseY):
+		return []sim.Action{{Kind: sim.ActionSetTarget, X: pos.X, Y: pos.Y, Target: g.world.NextTargetPriority(i.tower)}}, true
+	case i.upgradeButton.contains(mouseX, mouseY):
+		return []sim.Action{{Kind: sim.ActionUpgradeTower, X: pos.X, Y: pos.Y}}, true
+	case i.sellButton.contains(mouseX, mouseY):
+		return []sim.Action{{Kind: sim.ActionRemoveTower, X: pos.X, Y: pos.Y}}, true
+	}
+	return nil, false
+}
+
+// drawInspector outlines the inspected tower on the map and fills the
+// bottom bar with its stats and the buttons to upgrade or sell it
+func (g *Game) drawInspector(screen *ebiten.Image) {
+	i := &g.inspector
+	t := i.tower
+	m := g.world.Map
+	def := g.world.TowerDefFor(t.Type)
+
+	// The tower itself, with its range
+	cellSize := float32(m.CellSize)
+	x := float32(fieldOffsetX(m)) + float32(t.Position.X)*cellSize
+	y := uiHeight + float32(t.Position.Y)*cellSize
+	drawTower(screen, t, def, m, true)
+	vector.StrokeRect(screen, x, y, cellSize, cellSize, 2, inspectedColor, false)
+
+	// Stats in three columns
+	top := fieldBottom(m)
+	refund := sim.SellValue(t)
+	DrawText(screen, def.Name, 250, top+28, color.White)
+	stats := []inspectorStat{
+		{"Level", fmt.Sprintf("%d/%d", t.Level, 1+len(def.Tiers))},
+		effectStat(t),
+		{"Damage", fmt.Sprintf("%.1f", t.Damage)},
+		{"Range", fmt.Sprintf("%.1f cells", t.AttackRange/float64(m.CellSize))},
+		{"Fire rate", fmt.Sprintf("%.2f/s", t.FireRate)},
+		{"Health", fmt.Sprintf("%.0f/%.0f", t.Health, t.MaxHealth)},
+		{"Kills", fmt.Sprintf("%d", t.Kills)},
+		{"Dealt", fmt.Sprintf("%.0f", t.DamageDealt)},
+	}
+	for n, stat := range stats {
+		slot := n + 1 // The name takes the top of the first column
+		statX, statY := 250+slot/3*170, top+28+slot%3*22
+		DrawSmallText(screen, stat.label, statX, statY, statLabelColor)
+		DrawSmallText(screen, stat.value, statX+75, statY, color.White)
+	}
+
+	// Target, upgrade and sell buttons, greyed out when the upgrade can't be bought
+	upgrade := i.upgradeButton
+	canUpgrade := false
+	if tier := g.world.NextTier(t); tier != nil {
+		upgrade.text = fmt.Sprintf("Upgrade (%d)", tier.Cost)
+		canUpgrade = g.world.Money >= tier.Cost
+	} else {
+		upgrade.text = "Max level"
+	}
+	if !canUpgrade {
+		upgrade.color = disabledColor
+	}
+	sell := i.sellButton
+	sell.text = fmt.Sprintf("Sell (+%d)", refund)
+	target := i.targetButton
+	target.text = "Target: " + t.Target.String()
+
+	drawInspectorButton(screen, target, true, g.mouseX, g.mouseY)
+	drawInspectorButton(screen, upgrade, canUpgrade, g.mouseX, g.mouseY)
+	drawInspectorButton(screen, sell, true, g.mouseX, g.mouseY)
+}
+
+// effectStat describes what a tower's shots do besides damage, or shows
+// its refund when they do nothing more
+func effectStat(t *sim.Tower) inspectorStat {
+	switch {
+	case t.Chain > 0:
+		return inspectorStat{"Chain", fmt.Sprintf("%d x %.0f%%", t.Chain, t.ChainKeep*100)}
+	case t.BurnStacks > 0:
+		return inspectorStat{"Burn", fmt.Sprintf("%.1f/s x %d", t.BurnDamage, t.BurnStacks)}
+	case t.RootTicks > 0:
+		return inspectorStat{"Slow", fmt.Sprintf("%.0f%% + root", t.Slow*100)}
+	case t.Slow > 0:
+		return inspectorStat{"Slow", fmt.Sprintf("%.0f%% %.1fs", t.Slow*100, float64(t.SlowTicks)/sim.TicksPerSecond)}
+	}
+	return inspectorStat{"Refund", fmt.Sprintf("%d", sim.SellValue(t))}
+}
+
+// drawInspectorButton draws one of the inspector's buttons, lit up under
+// the mouse when it can be used
+func drawInspectorButton(screen *ebiten.Image, btn Button, enabled bool, mouseX, mouseY int) {
+	buttonColor := btn.color
+	if enabled && btn.contains(mouseX, mouseY) {
+		buttonColor = color.RGBA{0, 210, 255, 255}
+	}
+	vector.DrawFilledRect(screen, float32(btn.x), float32(btn.y),
+		float32(btn.width), float32(btn.height), buttonColor, true)
+	textWidth := MeasureTextWidth(btn.text, false)
+	DrawText(screen, btn.text, btn.x+(btn.width-textWidth)/2, btn.y+17, color.Black)
+}
@@ -6,380 +6,234 @@ import (
 	"image/color"
 	"bytes"
 	"image"
-	"math"
+
+	"argent/pkg/sim"
+)
+
+const (
+	uiHeight        = 60   // Height of UI area at top
+	bottomBarHeight = 100  // Height of the tower selection bar below the grid
+	minScreenWidth  = 1024 // Narrowest screen, wide enough for the HUD and tower buttons
+	borderThickness = 2
 )
 
-// GameMap represents the game map
-type GameMap struct {
-	width, height    int
-	cellSize        int
-	terrain         [][]TerrainType
-	towers          []*Tower
-	enemies         []*Enemy  // Track current enemies for attack coordination
-	gridLineColor   color.Color
-	borderColor     color.Color
-	backgroundColor color.Color
-	entrance        Point
-	entranceStart   int
-	entranceEnd     int
-	exit           Point
-	exitStart      int
-	exitEnd        int
-	uiHeight       int    // Height of UI area at top
-	gridOffsetX    float64 // Horizontal offset to center grid
-	backgroundImg   *ebiten.Image
+var (
+	gridLineColor = color.RGBA{35, 35, 35, 255}
+	borderColor   = color.RGBA{60, 60, 60, 255}
+	rockColor     = color.RGBA{45, 40, 38, 255}
+	rockEdgeColor = color.RGBA{70, 64, 60, 255}
+	noBuildColor  = color.RGBA{90, 30, 30, 60}
+	waterColor    = color.RGBA{25, 60, 110, 255}
+	waveColor     = color.RGBA{70, 120, 180, 255}
+	mudColor      = color.RGBA{70, 50, 30, 255}
+	mudSpotColor  = color.RGBA{95, 70, 45, 255}
+)
+
+// screenSize returns the logical screen size needed to show a map with the
+// UI bar above it and the tower selection bar below
+func screenSize(m *sim.GameMap) (int, int) {
+	width := max(minScreenWidth, m.Width*m.CellSize+16)
+	return width, uiHeight + m.Height*m.CellSize + bottomBarHeight
 }
 
-// NewGameMap creates a new game map
-func NewGameMap() *GameMap {
-	// Load embedded background image
+// fieldOffsetX returns the horizontal offset that centers the map on screen
+func fieldOffsetX(m *sim.GameMap) float64 {
+	screenW, _ := screenSize(m)
+	return float64(screenW-m.Width*m.CellSize) / 2
+}
+
+// fieldBottom returns the screen row just below the playing field
+func fieldBottom(m *sim.GameMap) int {
+	return uiHeight + m.Height*m.CellSize
+}
+
+// loadBackground decodes the embedded background image
+func loadBackground() *ebiten.Image {
 	img, _, err := image.Decode(bytes.NewReader(embeddedBackground))
 	if err != nil {
 		panic(err)
 	}
-	ebitenImg := ebiten.NewImageFromImage(img)
-	m := &GameMap{
-		width:           18,             // 18 cells wide
-		height:          12,             // 12 cells tall
-		cellSize:        56,             // 56px square cells (18 * 56 = 1008px width)
-		gridLineColor:   color.RGBA{35, 35, 35, 255},
-		borderColor:     color.RGBA{60, 60, 60, 255},
-		backgroundColor: color.RGBA{20, 20, 20, 255},
-		towers:          make([]*Tower, 0),
-		enemies:         make([]*Enemy, 0),  // Initialize enemies slice
-		uiHeight:        60,             // 60px UI height
-		gridOffsetX:     8,              // Center the grid (1024 - 18*56 = 16px/2 = 8)
-		backgroundImg:   ebitenImg,
-	}
-	
-	// Initialize terrain - all cells are empty by default
-	m.terrain = make([][]TerrainType, m.height)
-	for i := range m.terrain {
-		m.terrain[i] = make([]TerrainType, m.width)
-		for j := range m.terrain[i] {
-			m.terrain[i][j] = Empty
-		}
-	}
-
-	// Calculate entrance and exit areas (1 cell high, centered)
-	m.entranceStart = m.height / 2
-	m.entranceEnd = m.entranceStart
-	m.entrance = Point{0, m.height / 2}
-	
-	m.exitStart = m.entranceStart
-	m.exitEnd = m.entranceEnd
-	m.exit = Point{m.width - 1, m.height / 2}
-	
-	return m
+	return ebiten.NewImageFromImage(img)
 }
 
-// Draw draws the map
-func (m *GameMap) Draw(screen *ebiten.Image) {
+// drawMap draws the background, grid, borders and towers
+func (g *Game) drawMap(screen *ebiten.Image) {
+	m := g.world.Map
+	offsetX := fieldOffsetX(m)
+
 	// Draw background image at 20% visibility
 	op := &ebiten.DrawImageOptions{}
 	op.ColorM.Scale(1, 1, 1, 0.1) // Set alpha to 10%
-	screen.DrawImage(m.backgroundImg, op)
+	screen.DrawImage(g.backgroundImg, op)
 	
 	// Draw UI background slightly darker
 	vector.DrawFilledRect(
 		screen,
 		0,
 		0,
-		float32(m.width*m.cellSize),
-		float32(m.uiHeight),
+		float32(screen.Bounds().Dx()),
+		float32(uiHeight),
 		color.RGBA{15, 15, 15, 255},
 		false)
 	
+	// Draw rocks and no-build ground laid out by the map
+	drawTerrain(screen, m)
+
 	// Draw grid lines
-	for i := 0; i <= m.width; i++ {
-		x := m.gridOffsetX + float64(i * m.cellSize)
-		if i > 0 && i < m.width {
+	for i := 0; i <= m.Width; i++ {
+		x := offsetX + float64(i * m.CellSize)
+		if i > 0 && i < m.Width {
 			vector.StrokeLine(
 				screen,
 				float32(x),
-				float32(m.uiHeight),
+				float32(uiHeight),
 				float32(x),
-				float32(m.uiHeight+m.height*m.cellSize),
+				float32(uiHeight+m.Height*m.CellSize),
 				1,
-				m.gridLineColor,
+				gridLineColor,
 				false)
 		}
 	}
 	
-	for i := 0; i <= m.height; i++ {
-		y := float64(i*m.cellSize + m.uiHeight)
-		if i > 0 && i < m.height {
+	for i := 0; i <= m.Height; i++ {
+		y := float64(i*m.CellSize + uiHeight)
+		if i > 0 && i < m.Height {
 			vector.StrokeLine(
 				screen,
-				float32(m.gridOffsetX),
+				float32(offsetX),
 				float32(y),
-				float32(m.gridOffsetX + float64(m.width*m.cellSize)),
+				float32(offsetX + float64(m.Width*m.CellSize)),
 				float32(y),
 				1,
-				m.gridLineColor,
+				gridLineColor,
 				false)
 		}
 	}
 
-	// Draw borders with gaps for entrance and exit
-	borderThickness := float32(2)
-
-	// Top border (below UI)
-	vector.DrawFilledRect(
-		screen,
-		float32(m.gridOffsetX),
-		float32(m.uiHeight),
-		float32(m.width*m.cellSize),
-		borderThickness,
-		m.borderColor,
-		false)
-	
-	// Bottom border
-	vector.DrawFilledRect(
-		screen,
-		float32(m.gridOffsetX),
-		float32(m.uiHeight+m.height*m.cellSize)-borderThickness,
-		float32(m.width*m.cellSize),
-		borderThickness,
-		m.borderColor,
-		false)
-	
-	// Left border (with gap for entrance)
-	vector.DrawFilledRect(
-		screen,
-		float32(m.gridOffsetX),
-		float32(m.uiHeight),
-		borderThickness,
-		float32(m.entranceStart*m.cellSize),
-		m.borderColor,
-		false)
-	vector.DrawFilledRect(
-		screen,
-		float32(m.gridOffsetX),
-		float32(m.uiHeight+(m.entranceEnd+1)*m.cellSize),
-		borderThickness,
-		float32((m.height-m.entranceEnd-1)*m.cellSize),
-		m.borderColor,
-		false)
-	
-	// Right border (with gap for exit)
-	vector.DrawFilledRect(
-		screen,
-		float32(m.gridOffsetX+float64(m.width*m.cellSize)-float64(borderThickness)),
-		float32(m.uiHeight),
-		borderThickness,
-		float32(m.exitStart*m.cellSize),
-		m.borderColor,
-		false)
-	vector.DrawFilledRect(
-		screen,
-		float32(m.gridOffsetX+float64(m.width*m.cellSize)-float64(borderThickness)),
-		float32(m.uiHeight+(m.exitEnd+1)*m.cellSize),
-		borderThickness,
-		float32((m.height-m.exitEnd-1)*m.cellSize),
-		m.borderColor,
-		false)
+	// Draw borders with gaps for every entrance and exit
+	for _, edge := range []sim.Edge{sim.EdgeLeft, sim.EdgeRight, sim.EdgeTop, sim.EdgeBottom} {
+		drawBorder(screen, m, edge)
+	}
 
 	// Draw towers
-	for _, tower := range m.towers {
+	for _, tower := range m.Towers {
 		if tower != nil {
 			// Pass true if this is the selected tower
 			isSelected := false
-			mouseX, mouseY := ebiten.CursorPosition()
-			gridX, gridY := m.GetGridPosition(float64(mouseX), float64(mouseY))
-			if tower.position.X == gridX && tower.position.Y == gridY {
+			gridX, gridY := g.GetGridPosition(float64(g.mouseX), float64(g.mouseY))
+			if tower.Position.X == gridX && tower.Position.Y == gridY {
 				isSelected = true
 			}
-			tower.Draw(screen, isSelected)
+			drawTower(screen, tower, g.world.TowerDefFor(tower.Type), m, isSelected)
 		}
 	}
 }
 
 // GetGridPosition converts screen coordinates to grid coordinates
-func (m *GameMap) GetGridPosition(screenX, screenY float64) (int, int) {
+func (g *Game) GetGridPosition(screenX, screenY float64) (int, int) {
+	m := g.world.Map
+
 	// Adjust for UI height and grid offset
-	screenY -= float64(m.uiHeight)
-	screenX -= m.gridOffsetX
+	screenY -= float64(uiHeight)
+	screenX -= fieldOffsetX(m)
 	
-	gridX := int(screenX) / m.cellSize
-	gridY := int(screenY) / m.cellSize
+	gridX := int(screenX) / m.CellSize
+	gridY := int(screenY) / m.CellSize
 	
 	// Ensure coordinates are within bounds
 	if gridX < 0 {
 		gridX = 0
 	}
-	if gridX >= m.width {
-		gridX = m.width - 1
+	if gridX >= m.Width {
+		gridX = m.Width - 1
 	}
 	if gridY < 0 {
 		gridY = 0
 	}
-	if gridY >= m.height {
-		gridY = m.height - 1
+	if gridY >= m.Height {
+		gridY = m.Height - 1
 	}
 	
 	return gridX, gridY
 }
 
-// IsBlocked checks if a position is blocked by a tower or out of bounds
-func (m *GameMap) IsBlocked(x, y int) bool {
-	// Check bounds
-	if x < 0 || x >= m.width || y < 0 || y >= m.height {
-		return true
-	}
-	
-	// Always block cells with towers
-	if m.terrain[y][x] == TowerPlacement {
-		return true
-	}
-	
-	return false
-}
-
-// CanPlaceTower checks if a position is suitable for tower placement
-func (m *GameMap) CanPlaceTower(x, y int) bool {
-	// Can't place in entrance or exit areas
-	if x == 0 && (y >= m.entranceStart && y <= m.entranceEnd) {
-		return false
-	}
-	if x == m.width-1 && (y >= m.exitStart && y <= m.exitEnd) {
-		return false
-	}
-	
-	// Check if position is within bounds and empty
-	if x < 0 || x >= m.width || y < 0 || y >= m.height {
-		return false
-	}
-	return m.terrain[y][x] == Empty
-}
-
-// PlaceTower attempts to place a tower at the specified position
-func (m *GameMap) PlaceTower(x, y int) bool {
-	if !m.CanPlaceTower(x, y) {
-		return false
-	}
-	
-	// Check if placing the tower would block all paths
-	if !m.checkPathExists(x, y) {
-		return false
-	}
-	
-	m.terrain[y][x] = TowerPlacement
-	m.towers = append(m.towers, NewTower(currentGame.selectedTower, x, y))
-	return true
-}
-
-// GetTowerAt returns the tower at the specified position or nil if there isn't one
-func (m *GameMap) GetTowerAt(x, y int) *Tower {
-	for _, tower := range m.towers {
-		if tower.position.X == x && tower.position.Y == y {
-			return tower
-		}
-	}
-	return nil
-}
-
-// RemoveTower removes a tower from the specified position
-func (m *GameMap) RemoveTower(x, y int) {
-	if x >= 0 && x < m.width && y >= 0 && y < m.height {
-		m.terrain[y][x] = Empty
-		// Remove tower from towers slice
-		for i, tower := range m.towers {
-			pos := tower.GetPosition()
-			if pos.X == x && pos.Y == y {
-				m.towers = append(m.towers[:i], m.towers[i+1:]...)
-				break
+// drawTerrain draws rock, water and mud, and tints cells towers can't be
+// built on
+func drawTerrain(screen *ebiten.Image, m *sim.GameMap) {
+	offsetX := fieldOffsetX(m)
+	cellSize := float32(m.CellSize)
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			cellX := float32(offsetX) + float32(x)*cellSize
+			cellY := float32(uiHeight) + float32(y)*cellSize
+			switch m.Terrain[y][x] {
+			case sim.Rock:
+				vector.DrawFilledRect(screen, cellX+2, cellY+2, cellSize-4, cellSize-4, rockColor, false)
+				vector.StrokeRect(screen, cellX+2, cellY+2, cellSize-4, cellSize-4, 2, rockEdgeColor, false)
+			case sim.Water:
+				// Water fills the whole cell so neighbouring cells join into one pool
+				vector.DrawFilledRect(screen, cellX, cellY, cellSize, cellSize, waterColor, false)
+				for i := float32(1); i <= 2; i++ {
+					waveY := cellY + cellSize*i/3
+					vector.StrokeLine(screen, cellX+cellSize*0.2, waveY, cellX+cellSize*0.45, waveY-3, 1.5, waveColor, true)
+					vector.StrokeLine(screen, cellX+cellSize*0.45, waveY-3, cellX+cellSize*0.7, waveY, 1.5, waveColor, true)
+				}
+			case sim.Mud:
+				vector.DrawFilledRect(screen, cellX, cellY, cellSize, cellSize, mudColor, false)
+				vector.DrawFilledCircle(screen, cellX+cellSize*0.3, cellY+cellSize*0.35, cellSize*0.08, mudSpotColor, true)
+				vector.DrawFilledCircle(screen, cellX+cellSize*0.65, cellY+cellSize*0.6, cellSize*0.1, mudSpotColor, true)
+				vector.DrawFilledCircle(screen, cellX+cellSize*0.4, cellY+cellSize*0.75, cellSize*0.06, mudSpotColor, true)
+			case sim.NoBuild:
+				vector.DrawFilledRect(screen, cellX, cellY, cellSize, cellSize, noBuildColor, false)
 			}
 		}
 	}
 }
 
-// GetEntranceArea returns the entrance area coordinates
-func (m *GameMap) GetEntranceArea() (int, int, int) {
-	return m.entranceStart, m.entranceEnd, 0
-}
-
-// GetExitArea returns the exit area coordinates
-func (m *GameMap) GetExitArea() (int, int, int) {
-	return m.exitStart, m.exitEnd, m.width - 1
-}
-
-// GetTowersInRange returns all towers within range of a point
-func (m *GameMap) GetTowersInRange(x, y, range_ float64) []*Tower {
-	var nearbyTowers []*Tower
-	for _, tower := range m.towers {
-		if tower == nil {
+// drawBorder draws one edge of the playing field, leaving gaps where
+// entrances and exits cross it
+func drawBorder(screen *ebiten.Image, m *sim.GameMap, edge sim.Edge) {
+	offsetX := float32(fieldOffsetX(m))
+	cellSize := float32(m.CellSize)
+	fieldW := float32(m.Width) * cellSize
+	fieldH := float32(m.Height) * cellSize
+
+	// Mark the cells along the edge that are left open
+	length := m.Height
+	if edge == sim.EdgeTop || edge == sim.EdgeBottom {
+		length = m.Width
+	}
+	open := make([]bool, length)
+	for _, span := range append(append([]sim.Span(nil), m.Entrances...), m.Exits...) {
+		if span.Edge != edge {
 			continue
 		}
-		
-		towerX := float64(tower.position.X*m.cellSize + m.cellSize/2)
-		towerY := float64(tower.position.Y*m.cellSize + m.cellSize/2 + m.uiHeight)
-		
-		dx := towerX - x
-		dy := towerY - y
-		dist := math.Sqrt(dx*dx + dy*dy)
-		
-		if dist <= range_ {
-			nearbyTowers = append(nearbyTowers, tower)
+		for i := span.Start; i <= span.End && i < length; i++ {
+			open[i] = true
 		}
 	}
-	return nearbyTowers
-}
 
-// checkPathExists uses breadth-first search to verify if a path exists
-func (m *GameMap) checkPathExists(testX, testY int) bool {
-	// Temporarily place tower for testing
-	originalTerrain := m.terrain[testY][testX]
-	m.terrain[testY][testX] = TowerPlacement
-	
-	// Create visited array
-	visited := make([][]bool, m.height)
-	for i := range visited {
-		visited[i] = make([]bool, m.width)
-	}
-	
-	// Create queue for BFS
-	type QueueItem struct {
-		x, y int
-	}
-	queue := []QueueItem{}
-	
-	// Add all entrance points to queue
-	for y := m.entranceStart; y <= m.entranceEnd; y++ {
-		queue = append(queue, QueueItem{0, y})
-		visited[y][0] = true
-	}
-	
-	// BFS
-	pathExists := false
-	for len(queue) > 0 {
-		// Pop front of queue
-		current := queue[0]
-		queue = queue[1:]
-		
-		// Check if we've reached an exit point
-		if current.x == m.width-1 && current.y >= m.exitStart && current.y <= m.exitEnd {
-			pathExists = true
-			break
+	// Draw each closed run of cells as one segment
+	for start := 0; start < length; {
+		if open[start] {
+			start++
+			continue
 		}
-		
-		// Try all four directions
-		directions := [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
-		for _, dir := range directions {
-			newX := current.x + dir[0]
-			newY := current.y + dir[1]
-			
-			// Check bounds and if not visited and not blocked
-			if newX >= 0 && newX < m.width && newY >= 0 && newY < m.height && 
-			   !visited[newY][newX] && m.terrain[newY][newX] != TowerPlacement {
-				queue = append(queue, QueueItem{newX, newY})
-				visited[newY][newX] = true
-			}
+		end := start
+		for end < length && !open[end] {
+			end++
+		}
+		from, size := float32(start)*cellSize, float32(end-start)*cellSize
+		switch edge {
+		case sim.EdgeLeft:
+			vector.DrawFilledRect(screen, offsetX, uiHeight+from, borderThickness, size, borderColor, false)
+		case sim.EdgeRight:
+			vector.DrawFilledRect(screen, offsetX+fieldW-borderThickness, uiHeight+from, borderThickness, size, borderColor, false)
+		case sim.EdgeTop:
+			vector.DrawFilledRect(screen, offsetX+from, uiHeight, size, borderThickness, borderColor, false)
+		case sim.EdgeBottom:
+			vector.DrawFilledRect(screen, offsetX+from, uiHeight+fieldH-borderThickness, size, borderThickness, borderColor, false)
 		}
+		start = end
 	}
-	
-	// Restore original terrain
-	m.terrain[testY][testX] = originalTerrain
-	
-	return pathExists
-}
\ No newline at end of file
+}
@@ -0,0 +1,169 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"argent/pkg/sim"
+)
+
+var (
+	pathColor        = color.RGBA{255, 255, 255, 70}  // Route enemies take now
+	previewPathColor = color.RGBA{90, 200, 255, 200}  // Route they'd take with the hovered tower
+	longerPathColor  = color.RGBA{120, 220, 120, 255} // Readout when the tower lengthens the maze
+	shorterPathColor = color.RGBA{230, 120, 100, 255} // Readout when it shortens the maze
+)
+
+// drawPathPreview draws the routes enemies will take across the map and
+// their lengths, one set for each group of enemy types that weigh the map
+// alike. While the mouse is over a cell the selected tower could go on, it
+// also shows the routes they would take with the tower there.
+func (g *Game) drawPathPreview(screen *ebiten.Image) {
+	m := g.world.Map
+	types := g.world.Defs.EnemyTypes()
+	groups := m.RoutePaths(types)
+
+	var preview []sim.PathGroup
+	if g.inField(g.mouseY) {
+		gridX, gridY := g.GetGridPosition(float64(g.mouseX), float64(g.mouseY))
+		if m.CanPlaceTower(gridX, gridY) {
+			preview = m.RoutePathsWithTower(g.world.SelectedTower, gridX, gridY, types)
+		}
+	}
+
+	// With more than one group, each is drawn in the colors of its first
+	// enemy type and named in the readout
+	named := len(groups) > 1 || len(preview) > 1
+	for _, group := range groups {
+		clr := pathColor
+		if named {
+			clr = groupColor(group, g.world)
+		}
+		for _, path := range group.Paths {
+			drawPath(screen, m, path, clr)
+		}
+	}
+
+	// Readout in the middle of the top bar, where the wave info goes later
+	readout := make([]string, len(groups))
+	paths := 0
+	for i, group := range groups {
+		readout[i] = pathLengths(group.Paths)
+		if named {
+			readout[i] = groupName(group, g.world) + " " + readout[i]
+		}
+		paths += len(group.Paths)
+	}
+	label := "Path"
+	if paths > 1 {
+		label = "Paths"
+	}
+	DrawText(screen, fmt.Sprintf("%s: %s cells", label, strings.Join(readout, "; ")), 400, 20, color.White)
+
+	if preview == nil || slices.EqualFunc(groups, preview, samePaths) {
+		return
+	}
+	readout = readout[:0]
+	grew := 0
+	for _, group := range preview {
+		for _, path := range group.Paths {
+			drawPath(screen, m, path, previewPathColor)
+		}
+
+		// Compare with the paths the group's enemies take now
+		change := totalLength(group.Paths) - totalLength(pathsOf(groups, group.Types[0]))
+		text := fmt.Sprintf("%s (%+d)", pathLengths(group.Paths), change)
+		if named {
+			text = groupName(group, g.world) + " " + text
+		}
+		readout = append(readout, text)
+		grew += change
+	}
+
+	readoutColor := longerPathColor
+	if grew < 0 {
+		readoutColor = shorterPathColor
+	}
+	DrawText(screen, "With tower: "+strings.Join(readout, "; "), 400, 40, readoutColor)
+}
+
+// samePaths reports whether two groups have the same enemy types walking
+// the same paths
+func samePaths(a, b sim.PathGroup) bool {
+	return slices.Equal(a.Types, b.Types) && slices.EqualFunc(a.Paths, b.Paths, slices.Equal)
+}
+
+// pathsOf returns the paths enemies of a type walk in a list of groups
+func pathsOf(groups []sim.PathGroup, enemyType sim.EnemyType) [][]sim.Point {
+	for _, group := range groups {
+		if slices.Contains(group.Types, enemyType) {
+			return group.Paths
+		}
+	}
+	return nil
+}
+
+// groupName names a group by its first enemy type, with a plus when more
+// types walk its paths
+func groupName(group sim.PathGroup, w *sim.World) string {
+	name := w.EnemyDefFor(group.Types[0]).Name
+	if len(group.Types) > 1 {
+		name += "+"
+	}
+	return name
+}
+
+// groupColor is a see-through version of the body color of a group's first
+// enemy type
+func groupColor(group sim.PathGroup, w *sim.World) color.RGBA {
+	body := w.EnemyDefFor(group.Types[0]).PrimaryColor
+	return color.RGBA{body.R, body.G, body.B, 110}
+}
+
+// drawPath draws a line through the centers of a path's cells
+func drawPath(screen *ebiten.Image, m *sim.GameMap, path []sim.Point, clr color.RGBA) {
+	if len(path) == 0 {
+		return
+	}
+	offsetX := float32(fieldOffsetX(m))
+	center := func(cell sim.Point) (float32, float32) {
+		x, y := m.CellCenter(cell.X, cell.Y)
+		return float32(x) + offsetX, float32(y) + uiHeight
+	}
+
+	prevX, prevY := center(path[0])
+	vector.DrawFilledCircle(screen, prevX, prevY, 4, clr, true)
+	for _, cell := range path[1:] {
+		x, y := center(cell)
+		vector.StrokeLine(screen, prevX, prevY, x, y, 3, clr, true)
+		prevX, prevY = x, y
+	}
+	vector.DrawFilledCircle(screen, prevX, prevY, 4, clr, true)
+}
+
+// pathLengths lists the length in cells of each path, with a dash for a
+// route that is cut off
+func pathLengths(paths [][]sim.Point) string {
+	lengths := make([]string, len(paths))
+	for i, path := range paths {
+		lengths[i] = "-"
+		if path != nil {
+			lengths[i] = fmt.Sprint(len(path))
+		}
+	}
+	return strings.Join(lengths, ", ")
+}
+
+// totalLength adds up the lengths of paths in cells
+func totalLength(paths [][]sim.Point) int {
+	total := 0
+	for _, path := range paths {
+		total += len(path)
+	}
+	return total
+}
@@ -6,105 +6,66 @@ import (
 	"image/color"
 	"math"
 	"math/rand"
-)
-
-// ProjectileType represents different types of projectiles
-type ProjectileType int
 
-const (
-	DartProjectile ProjectileType = iota
-	BulletProjectile
-	LightningProjectile
-	FlameProjectile
-	FreezeProjectile
+	"argent/pkg/sim"
 )
 
-// Projectile represents a projectile shot from a tower
-type Projectile struct {
-	x, y        float64   // Current position
-	targetX     float64   // Target X position
-	targetY     float64   // Target Y position
-	speed       float64   // Movement speed
-	damage      float64   // Damage amount
-	projType    ProjectileType
+// projectileStyle holds the screen position and look of a projectile
+type projectileStyle struct {
+	x, y        float64   // Screen position
+	projType    sim.ProjectileType
 	size        float64   // Size for drawing
 	color       color.Color
 }
 
-// NewProjectile creates a new projectile
-func NewProjectile(startX, startY, targetX, targetY float64, projType ProjectileType, damage float64) *Projectile {
-	// Base projectile setup
-	proj := &Projectile{
-		x:        startX,
-		y:        startY,
-		targetX:  targetX,
-		targetY:  targetY,
-		speed:    5.0,    // Base speed for all projectiles
-		damage:   damage,
-		projType: projType,
+// newProjectileStyle builds the drawing parameters for a projectile
+func newProjectileStyle(proj *sim.Projectile, gameMap *sim.GameMap) projectileStyle {
+	// Base projectile setup, shifted onto the playing field
+	p := projectileStyle{
+		x:        proj.X + fieldOffsetX(gameMap),
+		y:        proj.Y + float64(uiHeight),
+		projType: proj.Type,
 		size:     8.0,    // Base size for projectiles
 	}
 
 	// Set specific colors based on tower type
-	switch projType {
-	case DartProjectile:
+	switch p.projType {
+	case sim.DartProjectile:
 		// Brighter version of tower bronze
-		proj.color = color.RGBA{255, 210, 120, 255}  // Brighter bronze
-		proj.size = 12.0  // Slightly larger for visibility
-		proj.speed = 6.0  // Faster than base
-	case BulletProjectile:
+		p.color = color.RGBA{255, 210, 120, 255}  // Brighter bronze
+		p.size = 12.0  // Slightly larger for visibility
+	case sim.BulletProjectile:
 		// Brighter version of tower gold
-		proj.color = color.RGBA{255, 235, 120, 255}  // Bright metallic gold
-		proj.size = 6.0   // Small but fast
-		proj.speed = 8.0  // Fastest projectile
-	case LightningProjectile:
+		p.color = color.RGBA{255, 235, 120, 255}  // Bright metallic gold
+		p.size = 6.0   // Small but fast
+	case sim.LightningProjectile:
 		// Brighter version of tower blue
-		proj.color = color.RGBA{120, 240, 255, 255}  // Intense bright electric blue
-		proj.size = 14.0  // Larger for lightning effect
-		proj.speed = 7.0  // Fast
-	case FlameProjectile:
+		p.color = color.RGBA{120, 240, 255, 255}  // Intense bright electric blue
+		p.size = 14.0  // Larger for lightning effect
+	case sim.FlameProjectile:
 		// Brighter version of tower orange-red
-		proj.color = color.RGBA{255, 140, 60, 255}  // Vivid orange-red
-		proj.size = 7.0   // Smaller base size for flame effect
-		proj.speed = 4.0  // Slower but area effect
-	case FreezeProjectile:
+		p.color = color.RGBA{255, 140, 60, 255}  // Vivid orange-red
+		p.size = 7.0   // Smaller base size for flame effect
+	case sim.FreezeProjectile:
 		// Brighter version of tower ice blue
-		proj.color = color.RGBA{200, 250, 255, 255}  // Brilliant ice blue
-		proj.size = 11.0   // Larger for snowflake
-		proj.speed = 5.0  // Medium speed
+		p.color = color.RGBA{200, 250, 255, 255}  // Brilliant ice blue
+		p.size = 11.0   // Larger for snowflake
 	}
 
-	return proj
+	return p
 }
 
-// Update moves the projectile and returns true if it reached its target
-func (p *Projectile) Update() bool {
-	// Calculate direction to target
-	dx := p.targetX - p.x
-	dy := p.targetY - p.y
-	dist := math.Sqrt(dx*dx + dy*dy)
-	
-	// If we're very close to target, we've hit
-	if dist < p.speed {
-		return true
-	}
-	
-	// Move towards target
-	p.x += (dx / dist) * p.speed
-	p.y += (dy / dist) * p.speed
-	
-	return false
-}
+// drawProjectile draws a projectile
+func drawProjectile(screen *ebiten.Image, proj *sim.Projectile, gameMap *sim.GameMap, fx *rand.Rand) {
+	p := newProjectileStyle(proj, gameMap)
 
-// Draw draws the projectile
-func (p *Projectile) Draw(screen *ebiten.Image) {
 	// Calculate angle to target for rotation
-	dx := p.targetX - p.x
-	dy := p.targetY - p.y
+	dx := proj.TargetX - proj.X
+	dy := proj.TargetY - proj.Y
 	angle := math.Atan2(dy, dx)
 
 	switch p.projType {
-	case DartProjectile:
+	case sim.DartProjectile:
 		// Draw dart as an arrow with tail
 		length := p.size * 1.5
 		headSize := p.size * 0.5
@@ -138,7 +99,7 @@ func (p *Projectile) Draw(screen *ebiten.Image) {
 			p.color,
 			true)
 
-	case BulletProjectile:
+	case sim.BulletProjectile:
 		// Draw bullet as a fast-moving metallic projectile with trail
 		// Main bullet
 		vector.DrawFilledCircle(screen,
@@ -157,7 +118,7 @@ func (p *Projectile) Draw(screen *ebiten.Image) {
 			color.RGBA{255, 220, 120, 128}, // Transparent trail
 			true)
 
-	case LightningProjectile:
+	case sim.LightningProjectile:
 		// Draw lightning as multiple connected zigzag segments with more dramatic effect
 		segmentLength := p.size * 0.8
 		numSegments := 5  // More segments
@@ -170,8 +131,8 @@ func (p *Projectile) Draw(screen *ebiten.Image) {
 			nextY := p.y + math.Sin(angle)*segmentLength*float64(i+1)
 			
 			// Add randomized offset
-			nextX += math.Cos(angle+math.Pi/2) * offset * (0.8 + rand.Float64()*0.4)
-			nextY += math.Sin(angle+math.Pi/2) * offset * (0.8 + rand.Float64()*0.4)
+			nextX += math.Cos(angle+math.Pi/2) * offset * (0.8 + fx.Float64()*0.4)
+			nextY += math.Sin(angle+math.Pi/2) * offset * (0.8 + fx.Float64()*0.4)
 			
 			// Draw wider outer glow
 			vector.StrokeLine(screen,
@@ -193,8 +154,8 @@ func (p *Projectile) Draw(screen *ebiten.Image) {
 			nextX := p.x + math.Cos(angle)*segmentLength*float64(i+1)
 			nextY := p.y + math.Sin(angle)*segmentLength*float64(i+1)
 			
-			nextX += math.Cos(angle+math.Pi/2) * offset * (0.8 + rand.Float64()*0.4)
-			nextY += math.Sin(angle+math.Pi/2) * offset * (0.8 + rand.Float64()*0.4)
+			nextX += math.Cos(angle+math.Pi/2) * offset * (0.8 + fx.Float64()*0.4)
+			nextY += math.Sin(angle+math.Pi/2) * offset * (0.8 + fx.Float64()*0.4)
 			
 			// Draw middle layer
 			vector.StrokeLine(screen,
@@ -215,8 +176,8 @@ func (p *Projectile) Draw(screen *ebiten.Image) {
 			lastX, lastY = nextX, nextY
 			
 			// Add occasional branch lightning
-			if rand.Float64() < 0.3 { // 30% chance per segment
-				branchAngle := angle + (rand.Float64()-0.5)*1.0 // Random branch direction
+			if fx.Float64() < 0.3 { // 30% chance per segment
+				branchAngle := angle + (fx.Float64()-0.5)*1.0 // Random branch direction
 				branchLength := segmentLength * 0.5 // Half length of main bolt
 				
 				// Draw branch with same layered effect
@@ -246,7 +207,7 @@ func (p *Projectile) Draw(screen *ebiten.Image) {
 			}
 		}
 
-	case FlameProjectile:
+	case sim.FlameProjectile:
 		// Draw flame as multiple particles in a flame shape with more dramatic effect
 		numParticles := 5  // Fewer particles for more compact flame
 		baseSize := p.size * 0.5  // Smaller base particle size
@@ -255,7 +216,7 @@ func (p *Projectile) Draw(screen *ebiten.Image) {
 			// Calculate particle position in flame pattern
 			spread := 0.3 // Narrower flame spread
 			particleDistance := float64(i) * baseSize * 0.5  // More compact spacing
-			particleAngle := angle + (rand.Float64()-0.5)*spread
+			particleAngle := angle + (fx.Float64()-0.5)*spread
 			
 			// Vary particle size with more dramatic falloff
 			particleSize := baseSize * (1.0 - float64(i)/float64(numParticles))
@@ -285,7 +246,7 @@ func (p *Projectile) Draw(screen *ebiten.Image) {
 				true)
 		}
 
-	case FreezeProjectile:
+	case sim.FreezeProjectile:
 		// Draw freeze as a snowflake pattern
 		// Draw main crystal shape
 		for i := 0; i < 6; i++ {
@@ -331,18 +292,3 @@ func (p *Projectile) Draw(screen *ebiten.Image) {
 			true)
 	}
 }
-
-// GetPosition returns the current position of the projectile
-func (p *Projectile) GetPosition() (float64, float64) {
-	return p.x, p.y
-}
-
-// GetDamage returns the damage amount of the projectile
-func (p *Projectile) GetDamage() float64 {
-	return p.damage
-}
-
-// GetProjectileType returns the type of the projectile
-func (p *Projectile) GetProjectileType() ProjectileType {
-	return p.projType
-}
\ No newline at end of file
@@ -4,55 +4,37 @@ import (
 	"image/color"
 	"math"
 	"math/rand"
-	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
-)
-
-// TowerType represents different types of towers
-type TowerType int
 
-const (
-	DartTower TowerType = iota
-	BulletTower
-	LightningTower
-	FlameTower
-	FreezeTower
-	ForkTower
+	"argent/pkg/sim"
 )
 
-// Tower represents a defensive tower
-type Tower struct {
-	position        Point
-	towerType       TowerType
-	damage          float64
-	attackRange     float64
-	fireRate        float64
-	cost            int
-	lastShot        float64
-	level           int
-	sprite          *ebiten.Image
-	canFireDiagonal bool
-	health          float64
-	maxHealth       float64
-	damageState     int
-	underAttack     int
-}
+// towerSprites caches one sprite per tower definition
+var towerSprites = map[*sim.TowerDef]*ebiten.Image{}
 
-var forkTowerCount = 0
+// towerSprite returns the cached sprite for a tower definition
+func towerSprite(def *sim.TowerDef) *ebiten.Image {
+	sprite, ok := towerSprites[def]
+	if !ok {
+		sprite = getTowerSprite(def)
+		towerSprites[def] = sprite
+	}
+	return sprite
+}
 
-// Draw draws the tower
-func (t *Tower) Draw(screen *ebiten.Image, selected bool) {
-	gameMap := GetGameMap()
+// drawTower draws a tower as its definition describes it, with its range
+// circle if selected
+func drawTower(screen *ebiten.Image, t *sim.Tower, def *sim.TowerDef, gameMap *sim.GameMap, selected bool) {
 	if gameMap == nil {
 		return
 	}
 
 	// Calculate tower position
-	cellSize := float64(gameMap.cellSize)
-	x := float64(t.position.X) * cellSize
-	y := float64(t.position.Y) * cellSize + float64(gameMap.uiHeight)
+	cellSize := float64(gameMap.CellSize)
+	x := float64(t.Position.X)*cellSize + fieldOffsetX(gameMap)
+	y := float64(t.Position.Y)*cellSize + float64(uiHeight)
 
 	// Draw range circle if selected
 	if selected {
@@ -61,22 +43,19 @@ func (t *Tower) Draw(screen *ebiten.Image, selected bool) {
 		vector.StrokeCircle(screen,
 			float32(centerX),
 			float32(centerY),
-			float32(t.attackRange),
+			float32(t.AttackRange),
 			1.5,
 			color.RGBA{160, 160, 160, 100},
 			true)
 	}
 
-	if t.sprite == nil {
-		t.sprite = getTowerSprite(t.towerType)
-	}
-
-	if t.sprite != nil {
+	sprite := towerSprite(def)
+	if sprite != nil {
 		op := &ebiten.DrawImageOptions{}
 
 		// Scale sprite to fit cell (slightly smaller)
-		spriteW := float64(t.sprite.Bounds().Dx())
-		spriteH := float64(t.sprite.Bounds().Dy())
+		spriteW := float64(sprite.Bounds().Dx())
+		spriteH := float64(sprite.Bounds().Dy())
 		scale := (cellSize * 0.9) / math.Max(spriteW, spriteH)
 		op.GeoM.Scale(scale, scale)
 
@@ -89,24 +68,26 @@ func (t *Tower) Draw(screen *ebiten.Image, selected bool) {
 		)
 
 		// Apply red flash if under attack
-		if t.underAttack > 0 {
+		if t.UnderAttack > 0 {
 			// Get the original color from the tower sprite
-			origR, origG, origB, _ := t.GetTowerColor()
+			origR, origG, origB, _ := towerColor(def)
 			// Calculate brightness using perceived luminance
 			brightness := (float64(origR)*0.299 + float64(origG)*0.587 + float64(origB)*0.114) / 255.0
 			// Keep same brightness but shift to red
 			redValue := uint8(brightness * 255)
 			op.ColorScale.Scale(float32(redValue)/255.0, 0, 0, 1.0)
-			t.underAttack--
 		}
 
-		screen.DrawImage(t.sprite, op)
+		screen.DrawImage(sprite, op)
 
 		// Draw damage overlay
-		t.DrawDamageOverlay(screen, x, y, cellSize)
+		drawDamageOverlay(screen, t, x, y, cellSize)
+
+		// Show how many upgrades the tower has bought
+		drawTierPips(screen, t, x, y, cellSize)
 
 		// Draw small skull if under attack
-		if t.underAttack > 0 {
+		if t.UnderAttack > 0 {
 			skullSprite := createSpriteFromArt(SharedSkull, color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 0, 0})
 			if skullSprite != nil {
 				skullW := float64(skullSprite.Bounds().Dx())
@@ -126,187 +107,34 @@ func (t *Tower) Draw(screen *ebiten.Image, selected bool) {
 	}
 }
 
-// Rest of the tower.go code remains unchanged
-func (t *Tower) Update(enemies []*Enemy) []*Projectile {
-	var closestEnemy *Enemy
-	closestDist := t.attackRange
-
-	gameMap := GetGameMap()
-	if gameMap == nil {
-		return nil
-	}
-
-	// Calculate tower center position
-	towerX := float64(t.position.X*gameMap.cellSize + gameMap.cellSize/2)
-	towerY := float64(t.position.Y*gameMap.cellSize + gameMap.cellSize/2 + gameMap.uiHeight)
-
-	for _, enemy := range enemies {
-		if enemy == nil {
-			continue
-		}
-
-		dx := enemy.x - towerX
-		dy := enemy.y - towerY
-		dist := math.Sqrt(dx*dx + dy*dy)
-
-		if dist >= closestDist {
-			continue
-		}
-
-		if !t.canFireDiagonal {
-			angle := math.Atan2(dy, dx) * 180 / math.Pi
-			if angle < 0 {
-				angle += 360
-			}
-
-			isCardinal := false
-			for _, cardinal := range []float64{0, 90, 180, 270} {
-				angleDiff := math.Abs(angle - cardinal)
-				if angleDiff <= 22.5 || angleDiff >= 337.5 {
-					isCardinal = true
-					break
-				}
-			}
-
-			if !isCardinal {
-				continue
-			}
-		}
-
-		closestDist = dist
-		closestEnemy = enemy
-	}
-
-	if closestEnemy != nil && t.canShoot() {
-		var projType ProjectileType
-		switch t.towerType {
-		case DartTower:
-			projType = DartProjectile
-		case BulletTower:
-			projType = BulletProjectile
-		case LightningTower:
-			projType = LightningProjectile
-		case FlameTower:
-			projType = FlameProjectile
-		case FreezeTower:
-			projType = FreezeProjectile
-		}
-
-		proj := NewProjectile(
-			towerX,
-			towerY,
-			closestEnemy.x,
-			closestEnemy.y,
-			projType,
-			t.damage,
-		)
-
-		t.lastShot = float64(time.Now().UnixNano()) / 1e9
-		PlayTowerShootSound()
-		return []*Projectile{proj}
-	}
-
-	return nil
-}
-
-func (t *Tower) canShoot() bool {
-	currentTime := float64(time.Now().UnixNano()) / 1e9
-	return currentTime - t.lastShot >= 1.0/t.fireRate
-}
-
-func (t *Tower) GetPosition() Point {
-	return t.position
-}
-
-func NewTower(towerType TowerType, x, y int) *Tower {
-	if towerType == ForkTower && forkTowerCount >= 10 {
-		return nil
-	}
-
-	tower := &Tower{
-		position:  Point{x, y},
-		towerType: towerType,
-		level:     1,
-		health:    100,
-		maxHealth: 100,
-	}
-
-	gameMap := GetGameMap()
-	if gameMap == nil {
-		return nil
-	}
-	cellSize := gameMap.cellSize
-
-	switch towerType {
-	case DartTower:
-		tower.level = 1
-		tower.damage = 1.0
-		tower.attackRange = 2 * float64(cellSize)
-		tower.fireRate = 1.0
-		tower.sprite = getTowerSprite(DartTower)
-		tower.canFireDiagonal = true
-	case BulletTower:
-		tower.level = 2
-		tower.damage = 1.0 * 1.1
-		tower.attackRange = 3 * float64(cellSize)
-		tower.fireRate = 1.2
-		tower.sprite = getTowerSprite(BulletTower)
-		tower.canFireDiagonal = true
-	case LightningTower:
-		tower.level = 3
-		tower.damage = 1.0 * 1.2
-		tower.attackRange = 3 * float64(cellSize)
-		tower.fireRate = 0.8
-		tower.sprite = getTowerSprite(LightningTower)
-		tower.canFireDiagonal = true
-	case FlameTower:
-		tower.level = 4
-		tower.damage = 1.0 * 1.3
-		tower.attackRange = 2 * float64(cellSize)
-		tower.fireRate = 3.0
-		tower.sprite = getTowerSprite(FlameTower)
-		tower.canFireDiagonal = false
-	case FreezeTower:
-		tower.level = 5
-		tower.damage = 0
-		tower.attackRange = 2 * float64(cellSize)
-		tower.fireRate = 1.0
-		tower.sprite = getTowerSprite(FreezeTower)
-		tower.canFireDiagonal = true
-		tower.cost = 100
-	case ForkTower:
-		tower.level = 6
-		tower.damage = 2.0
-		tower.attackRange = 4 * float64(cellSize)
-		tower.fireRate = 1.5
-		tower.sprite = getTowerSprite(ForkTower)
-		tower.canFireDiagonal = true
-		tower.cost = 150
+// drawTierPips draws a gold pip along the top of a tower's cell for each
+// upgrade it has bought
+func drawTierPips(screen *ebiten.Image, t *sim.Tower, x, y, cellSize float64) {
+	upgrades := t.Level - 1
+	if upgrades <= 0 {
+		return
 	}
 
-	if towerType == ForkTower {
-		forkTowerCount++
+	pipSize := cellSize * 0.12
+	spacing := pipSize * 1.6
+	startX := x + cellSize/2 - spacing*float64(upgrades-1)/2
+	for i := 0; i < upgrades; i++ {
+		pipX := float32(startX + spacing*float64(i))
+		pipY := float32(y + pipSize)
+		vector.DrawFilledCircle(screen, pipX, pipY, float32(pipSize/2)+1, color.RGBA{60, 40, 0, 255}, true)
+		vector.DrawFilledCircle(screen, pipX, pipY, float32(pipSize/2), color.RGBA{255, 210, 60, 255}, true)
 	}
-
-	return tower
-}
-
-func (t *Tower) TakeDamage(damage float64) bool {
-	t.health = math.Max(0, t.health - damage)  // Prevent negative health
-	t.underAttack = 30  // Flash duration (0.5 seconds at 60fps)
-
-	// Return true if tower is destroyed
-	return t.health <= 0
 }
 
-func (t *Tower) DrawDamageOverlay(screen *ebiten.Image, x, y, size float64) {
+// drawDamageOverlay chips away at a tower sprite as it loses health
+func drawDamageOverlay(screen *ebiten.Image, t *sim.Tower, x, y, size float64) {
 	// Only draw damage effects if tower is damaged
-	if t.health < t.maxHealth {
+	if t.Health < t.MaxHealth {
 		// Calculate how damaged the tower is
-		damagePercent := 1.0 - (t.health / t.maxHealth)
+		damagePercent := 1.0 - (t.Health / t.MaxHealth)
 
 		// Use consistent random seeding based on tower position
-		seed := int64(t.position.X*1000 + t.position.Y)
+		seed := int64(t.Position.X*1000 + t.Position.Y)
 		r := rand.New(rand.NewSource(seed))
 
 		// Create a grid of cells that will be potentially removed
@@ -387,23 +215,12 @@ func (t *Tower) DrawDamageOverlay(screen *ebiten.Image, x, y, size float64) {
 	}
 }
 
-func (t *Tower) GetTowerColor() (uint8, uint8, uint8, uint8) {
-	switch t.towerType {
-	case DartTower:
-		return 200, 150, 80, 255
-	case BulletTower:
-		return 240, 190, 90, 255
-	case LightningTower:
-		return 50, 200, 255, 255
-	case FlameTower:
-		return 255, 100, 50, 255
-	case FreezeTower:
-		return 140, 220, 255, 255
-	case ForkTower:
-		return 20, 255, 200, 255
-	default:
+// towerColor returns the base color of a tower definition
+func towerColor(def *sim.TowerDef) (uint8, uint8, uint8, uint8) {
+	if def == nil {
 		return 200, 200, 200, 255
 	}
+	return def.Color.R, def.Color.G, def.Color.B, def.Color.A
 }
 
 func drawCracks(screen *ebiten.Image, x, y, size float64, crackColor color.Color, count int) {
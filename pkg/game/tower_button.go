@@ -3,6 +3,7 @@ package game
 import (
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
+	"argent/pkg/sim"
 	"image/color"
 	"fmt"
 	"math"
@@ -10,7 +11,7 @@ import (
 
 // TowerButton represents a selectable tower in the UI
 type TowerButton struct {
-	tower      TowerType
+	tower      sim.TowerType
 	x, y       int
 	width      int
 	height     int
@@ -18,52 +19,21 @@ type TowerButton struct {
 	sprite     *ebiten.Image
 	name       string
 	cost       int
+	antiAir    bool
 }
 
-// NewTowerButton creates a new tower selection button
-func NewTowerButton(tower TowerType, x, y int) *TowerButton {
-	var name string
-	switch tower {
-	case DartTower:
-		name = "Dart"
-	case BulletTower:
-		name = "Bullet"
-	case LightningTower:
-		name = "Lightning"
-	case FlameTower:
-		name = "Flame"
-	case FreezeTower:
-		name = "Freeze"
-	case ForkTower:
-		name = "Fork"
-	}
-
-	// Calculate cost based on tower type
-	var cost int
-	switch tower {
-	case DartTower:
-		cost = 10  // Basic tower
-	case BulletTower:
-		cost = 25  // Better range and speed
-	case LightningTower:
-		cost = 40  // High damage
-	case FlameTower:
-		cost = 60  // Area damage
-	case FreezeTower:
-		cost = 75  // Most expensive - powerful effect
-	case ForkTower:
-		cost = 150 // Electric fork tower with large range
-	}
-
+// NewTowerButton creates a new tower selection button for a tower definition
+func NewTowerButton(def *sim.TowerDef, x, y int) *TowerButton {
 	return &TowerButton{
-		tower:    tower,
+		tower:    def.Type,
 		x:        x,
 		y:        y-5,     // Move up slightly to make room for extended box
 		width:    80,      // Width of tower button
 		height:   65,      // Extended height to cover tower base
-		sprite:   getTowerSprite(tower),
-		name:     name,
-		cost:     cost,
+		sprite:   getTowerSprite(def),
+		name:     def.Name,
+		cost:     def.Cost,
+		antiAir:  def.AntiAir,
 	}
 }
 
@@ -121,6 +91,15 @@ func (tb *TowerButton) Draw(screen *ebiten.Image, canAfford bool) {
 		screen.DrawImage(tb.sprite, op)
 	}
 
+	// Mark towers that can hit flyers with a sky-blue chevron
+	if tb.antiAir {
+		cx := float32(tb.x + tb.width - 10)
+		cy := float32(tb.y + 8)
+		skyBlue := color.RGBA{120, 200, 255, 255}
+		vector.StrokeLine(screen, cx-5, cy+4, cx, cy-1, 2, skyBlue, true)
+		vector.StrokeLine(screen, cx, cy-1, cx+5, cy+4, 2, skyBlue, true)
+	}
+
 	// Draw cost in the middle of the button
 	costText := fmt.Sprintf("%d", tb.cost)
 	
@@ -0,0 +1,179 @@
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Reasons a command can be rejected. Errors returned by the command methods
+// wrap one of these, so callers can test them with errors.Is.
+var (
+	ErrNotEnoughPoints = errors.New("not enough points")
+	ErrBlocksPath      = errors.New("tower would block the path")
+	ErrTrapsEnemy      = errors.New("tower would trap an enemy")
+	ErrEnemyInTheWay   = errors.New("an enemy is standing there")
+	ErrMaxTier         = errors.New("tower is fully upgraded")
+	ErrBadTarget       = errors.New("tower can't use that target priority")
+	ErrCellOccupied    = errors.New("cell is occupied")
+	ErrCellReserved    = errors.New("cannot build on the entrance or exit")
+	ErrUnbuildable     = errors.New("cannot build on this terrain")
+	ErrOutOfBounds     = errors.New("cell is outside the map")
+	ErrTowerLimit      = errors.New("tower limit reached")
+	ErrNoTower         = errors.New("no tower at cell")
+	ErrUnknownTower    = errors.New("unknown tower type")
+	ErrWrongState      = errors.New("not possible right now")
+	ErrInvalidMap      = errors.New("map can't be played")
+)
+
+// SelectTower chooses the tower type used by ActionPlaceTower
+func (w *World) SelectTower(towerType TowerType) error {
+	if w.TowerDefFor(towerType) == nil {
+		return fmt.Errorf("%w: %d", ErrUnknownTower, towerType)
+	}
+	w.SelectedTower = towerType
+	return nil
+}
+
+// PlaceTower buys a tower of the given type and builds it on a grid cell
+func (w *World) PlaceTower(towerType TowerType, x, y int) (*Tower, error) {
+	def := w.TowerDefFor(towerType)
+	if def == nil {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownTower, towerType)
+	}
+	if w.State == EditorState {
+		return nil, fmt.Errorf("%w: the map editor is open", ErrWrongState)
+	}
+
+	// Check if we have enough points
+	if w.Money < def.Cost {
+		return nil, fmt.Errorf("%w: need %d, have %d", ErrNotEnoughPoints, def.Cost, w.Money)
+	}
+
+	if def.Limit > 0 && w.Map.CountTowers(towerType) >= def.Limit {
+		return nil, fmt.Errorf("%w: %d %s towers", ErrTowerLimit, def.Limit, def.Name)
+	}
+
+	// Enemies already on the field need a way out too
+	if err := w.Map.checkPlacement(x, y, w.Enemies); err != nil {
+		return nil, fmt.Errorf("%w at %d,%d", err, x, y)
+	}
+	tower := w.Map.buildTower(towerType, x, y)
+
+	// Tower was placed successfully, deduct points
+	w.Money -= def.Cost
+	w.Events.Publish(Event{Kind: EventTowerPlaced, Tower: tower, Points: def.Cost})
+	return tower, nil
+}
+
+// SellTower removes the tower on a grid cell and returns the points refunded
+func (w *World) SellTower(x, y int) (int, error) {
+	if w.State == EditorState {
+		return 0, fmt.Errorf("%w: the map editor is open", ErrWrongState)
+	}
+	tower := w.Map.GetTowerAt(x, y)
+	if tower == nil {
+		return 0, fmt.Errorf("%w %d,%d", ErrNoTower, x, y)
+	}
+
+	refund := SellValue(tower)
+	w.Money += refund
+	w.Map.RemoveTower(x, y)
+	w.Events.Publish(Event{Kind: EventTowerSold, Tower: tower, Points: refund})
+	return refund, nil
+}
+
+// UpgradeTower buys the next tier for the tower on a grid cell
+func (w *World) UpgradeTower(x, y int) (*Tower, error) {
+	if w.State == EditorState {
+		return nil, fmt.Errorf("%w: the map editor is open", ErrWrongState)
+	}
+	tower := w.Map.GetTowerAt(x, y)
+	if tower == nil {
+		return nil, fmt.Errorf("%w %d,%d", ErrNoTower, x, y)
+	}
+	tier := w.NextTier(tower)
+	if tier == nil {
+		return nil, fmt.Errorf("%w at level %d", ErrMaxTier, tower.Level)
+	}
+	if w.Money < tier.Cost {
+		return nil, fmt.Errorf("%w: need %d, have %d", ErrNotEnoughPoints, tier.Cost, w.Money)
+	}
+
+	w.Money -= tier.Cost
+	tower.Cost += tier.Cost
+	tower.Level++
+	tower.Damage += tier.Damage
+	tower.AttackRange += tier.Range * float64(w.Map.CellSize)
+	tower.FireRate += tier.FireRate
+	tower.ShotEffects = w.TowerDefFor(tower.Type).effectsAt(tower.Level).shot(w.Map.CellSize)
+	if tier.Range > 0 {
+		w.Map.terrainChanged() // Enemies that avoid freeze range weigh cells by tower range
+	}
+	w.Events.Publish(Event{Kind: EventTowerUpgraded, Tower: tower, Points: tier.Cost})
+	return tower, nil
+}
+
+// SetTargetPriority changes how the tower on a grid cell picks its target
+func (w *World) SetTargetPriority(x, y int, priority TargetPriority) (*Tower, error) {
+	if w.State == EditorState {
+		return nil, fmt.Errorf("%w: the map editor is open", ErrWrongState)
+	}
+	tower := w.Map.GetTowerAt(x, y)
+	if tower == nil {
+		return nil, fmt.Errorf("%w %d,%d", ErrNoTower, x, y)
+	}
+	if !slices.Contains(w.TargetPriorities(tower.Type), priority) {
+		return nil, fmt.Errorf("%w: %s", ErrBadTarget, priority)
+	}
+	tower.Target = priority
+	return tower, nil
+}
+
+// SellValue is the refund a tower would give if sold now: what was paid
+// for it and its upgrades, scaled by its remaining health
+func SellValue(t *Tower) int {
+	healthPercent := t.Health / t.MaxHealth
+	return int(float64(t.Cost) * healthPercent)
+}
+
+// StartWaves leaves build mode and sends in the first wave
+func (w *World) StartWaves() error {
+	if w.State != BuildState {
+		return fmt.Errorf("%w: waves already started", ErrWrongState)
+	}
+	w.State = PlayState
+	w.Events.Publish(Event{Kind: EventWaveStarted, Wave: w.CurrentWave})
+	return nil
+}
+
+// Pause freezes a wave in progress
+func (w *World) Pause() error {
+	if w.State != PlayState {
+		return fmt.Errorf("%w: nothing to pause", ErrWrongState)
+	}
+	w.State = PausedState
+	return nil
+}
+
+// Resume continues a paused wave
+func (w *World) Resume() error {
+	if w.State != PausedState {
+		return fmt.Errorf("%w: game is not paused", ErrWrongState)
+	}
+	w.State = PlayState
+	return nil
+}
+
+// TogglePause pauses a running wave or resumes a paused one
+func (w *World) TogglePause() error {
+	if w.State == PausedState {
+		return w.Resume()
+	}
+	return w.Pause()
+}
+
+// Reset returns the match to build mode with a fresh map
+func (w *World) Reset() {
+	w.reset()
+}
@@ -0,0 +1,127 @@
+package sim
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCommandRejections(t *testing.T) {
+	defs := currentDefs()
+	towers, err := parseTowerDefs([]byte(`[{"id": "bullet", "limit": 1}]`), defs.Towers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defs.Towers = towers
+
+	// A wall with one gap at 3,2, which every path goes through
+	layout := gridLayout(t,
+		"...#...",
+		"...#...",
+		".......",
+		"...#...",
+		"...#...",
+	)
+
+	tests := []struct {
+		name  string
+		setup func(w *World)
+		do    func(w *World) error
+		want  error
+	}{
+		{"place", nil, func(w *World) error {
+			_, err := w.PlaceTower(DartTower, 1, 1)
+			return err
+		}, nil},
+		{"place without the points", func(w *World) { w.Money = w.TowerDefFor(DartTower).Cost - 1 }, func(w *World) error {
+			_, err := w.PlaceTower(DartTower, 1, 1)
+			return err
+		}, ErrNotEnoughPoints},
+		{"place in the only gap", nil, func(w *World) error {
+			_, err := w.PlaceTower(DartTower, 3, 2)
+			return err
+		}, ErrBlocksPath},
+		{"place on a tower", func(w *World) { w.PlaceTower(DartTower, 1, 1) }, func(w *World) error {
+			_, err := w.PlaceTower(DartTower, 1, 1)
+			return err
+		}, ErrCellOccupied},
+		{"place on the entrance", nil, func(w *World) error {
+			_, err := w.PlaceTower(DartTower, 0, 4)
+			return err
+		}, ErrCellReserved},
+		{"place on rock", nil, func(w *World) error {
+			_, err := w.PlaceTower(DartTower, 3, 0)
+			return err
+		}, ErrUnbuildable},
+		{"place off the map", nil, func(w *World) error {
+			_, err := w.PlaceTower(DartTower, 7, 0)
+			return err
+		}, ErrOutOfBounds},
+		{"place past the limit", func(w *World) { w.PlaceTower(BulletTower, 1, 1) }, func(w *World) error {
+			_, err := w.PlaceTower(BulletTower, 5, 1)
+			return err
+		}, ErrTowerLimit},
+		{"place an unknown tower", nil, func(w *World) error {
+			_, err := w.PlaceTower(TowerType(99), 1, 1)
+			return err
+		}, ErrUnknownTower},
+		{"place in the editor", func(w *World) { w.State = EditorState }, func(w *World) error {
+			_, err := w.PlaceTower(DartTower, 1, 1)
+			return err
+		}, ErrWrongState},
+		{"sell", func(w *World) { w.PlaceTower(DartTower, 1, 1) }, func(w *World) error {
+			_, err := w.SellTower(1, 1)
+			return err
+		}, nil},
+		{"sell an empty cell", nil, func(w *World) error {
+			_, err := w.SellTower(1, 1)
+			return err
+		}, ErrNoTower},
+		{"sell in the editor", func(w *World) { w.PlaceTower(DartTower, 1, 1); w.State = EditorState }, func(w *World) error {
+			_, err := w.SellTower(1, 1)
+			return err
+		}, ErrWrongState},
+		{"upgrade", func(w *World) { w.PlaceTower(DartTower, 1, 1) }, func(w *World) error {
+			_, err := w.UpgradeTower(1, 1)
+			return err
+		}, nil},
+		{"upgrade an empty cell", nil, func(w *World) error {
+			_, err := w.UpgradeTower(1, 1)
+			return err
+		}, ErrNoTower},
+		{"upgrade without the points", func(w *World) { w.PlaceTower(DartTower, 1, 1); w.Money = 0 }, func(w *World) error {
+			_, err := w.UpgradeTower(1, 1)
+			return err
+		}, ErrNotEnoughPoints},
+		{"upgrade past the last tier", func(w *World) {
+			w.PlaceTower(DartTower, 1, 1)
+			w.Money = 1000
+			for range w.TowerDefFor(DartTower).Tiers {
+				w.UpgradeTower(1, 1)
+			}
+		}, func(w *World) error {
+			_, err := w.UpgradeTower(1, 1)
+			return err
+		}, ErrMaxTier},
+		{"target unfrozen with a dart tower", func(w *World) { w.PlaceTower(DartTower, 1, 1) }, func(w *World) error {
+			_, err := w.SetTargetPriority(1, 1, TargetUnfrozen)
+			return err
+		}, ErrBadTarget},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newWorld(1, layout, defs)
+			if tt.setup != nil {
+				tt.setup(w)
+			}
+			money, towers := w.Money, len(w.Map.Towers)
+
+			err := tt.do(w)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+			if err != nil && (w.Money != money || len(w.Map.Towers) != towers) {
+				t.Error("a rejected command still changed the world")
+			}
+		})
+	}
+}
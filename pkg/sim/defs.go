@@ -0,0 +1,167 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// sliceOwner is a definition holding slices, which copies of it must not
+// share
+type sliceOwner interface {
+	ownSlices()
+}
+
+// parseDefs applies a definition table on top of existing definitions. The
+// table is a JSON list of objects keyed by "id"; each one is unmarshalled
+// over a copy of the current definition with that id, so an entry only
+// needs the fields it changes. The result is indexed by the id's type.
+func parseDefs[K ~int, T any](data []byte, base []*T, ids map[string]K) ([]*T, error) {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	defs := make([]*T, len(ids))
+	for i, def := range base {
+		if def != nil {
+			copied := *def
+			// Unmarshalling a list reuses the slice it lands in, which
+			// would write over the base definition's list
+			if owner, ok := any(&copied).(sliceOwner); ok {
+				owner.ownSlices()
+			}
+			defs[i] = &copied
+		}
+	}
+
+	for _, entry := range entries {
+		var key struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(entry, &key); err != nil {
+			return nil, err
+		}
+		index, ok := ids[key.ID]
+		if !ok {
+			return nil, fmt.Errorf("unknown id %q", key.ID)
+		}
+
+		if defs[index] == nil {
+			defs[index] = new(T)
+		}
+		if err := json.Unmarshal(entry, defs[index]); err != nil {
+			return nil, fmt.Errorf("%q: %w", key.ID, err)
+		}
+	}
+	return defs, nil
+}
+
+// Defs are the tower, enemy and wave definitions a match is played with.
+// Replays carry them, as a match only plays out the same way again with
+// the same stats.
+type Defs struct {
+	Towers  []*TowerDef
+	Enemies []*EnemyDef
+	Waves   *WaveScript
+}
+
+// defsFile is the layout Defs are stored in: complete definition tables,
+// in the layout of the definition files
+type defsFile struct {
+	Towers  json.RawMessage `json:"towers"`
+	Enemies json.RawMessage `json:"enemies"`
+	Waves   json.RawMessage `json:"waves"`
+}
+
+// currentDefs returns the definitions loaded for new matches
+func currentDefs() *Defs {
+	return &Defs{Towers: towerDefs, Enemies: enemyDefs, Waves: waveScript}
+}
+
+// tower returns the definition of a tower type, or nil if it has none
+func (d *Defs) tower(towerType TowerType) *TowerDef {
+	if towerType < 0 || int(towerType) >= len(d.Towers) {
+		return nil
+	}
+	return d.Towers[towerType]
+}
+
+// enemy returns the definition of an enemy type, or nil if it has none
+func (d *Defs) enemy(enemyType EnemyType) *EnemyDef {
+	if enemyType < 0 || int(enemyType) >= len(d.Enemies) {
+		return nil
+	}
+	return d.Enemies[enemyType]
+}
+
+// TowerTypes returns every defined tower type in menu order
+func (d *Defs) TowerTypes() []TowerType {
+	types := make([]TowerType, 0, len(d.Towers))
+	for _, def := range d.Towers {
+		if def != nil {
+			types = append(types, def.Type)
+		}
+	}
+	return types
+}
+
+// EnemyTypes returns every defined enemy type in type order
+func (d *Defs) EnemyTypes() []EnemyType {
+	types := make([]EnemyType, 0, len(d.Enemies))
+	for _, def := range d.Enemies {
+		if def != nil {
+			types = append(types, def.Type)
+		}
+	}
+	return types
+}
+
+// MarshalJSON stores the definitions as complete definition tables
+func (d *Defs) MarshalJSON() ([]byte, error) {
+	var file defsFile
+	var err error
+	if file.Towers, err = json.Marshal(defined(d.Towers)); err != nil {
+		return nil, err
+	}
+	if file.Enemies, err = json.Marshal(defined(d.Enemies)); err != nil {
+		return nil, err
+	}
+	if file.Waves, err = json.Marshal(d.Waves); err != nil {
+		return nil, err
+	}
+	return json.Marshal(file)
+}
+
+// UnmarshalJSON reads definition tables stored by MarshalJSON and checks
+// them the same way definition files are checked
+func (d *Defs) UnmarshalJSON(data []byte) error {
+	var file defsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+	towers, err := parseTowerDefs(file.Towers, nil)
+	if err != nil {
+		return fmt.Errorf("towers: %w", err)
+	}
+	enemies, err := parseEnemyDefs(file.Enemies, nil)
+	if err != nil {
+		return fmt.Errorf("enemies: %w", err)
+	}
+	waves, err := parseWaves(file.Waves)
+	if err != nil {
+		return fmt.Errorf("waves: %w", err)
+	}
+	*d = Defs{Towers: towers, Enemies: enemies, Waves: waves}
+	return nil
+}
+
+// defined drops the types a definition table has no entry for
+func defined[T any](defs []*T) []*T {
+	entries := make([]*T, 0, len(defs))
+	for _, def := range defs {
+		if def != nil {
+			entries = append(entries, def)
+		}
+	}
+	return entries
+}
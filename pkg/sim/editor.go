@@ -0,0 +1,184 @@
+package sim
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OpenEditor switches to the map editor. The map can only be edited before
+// the waves start, and towers the player has built are cleared. Editing
+// changes a copy of the layout, so the map the match was started with is
+// left alone.
+func (w *World) OpenEditor() error {
+	if w.State != BuildState {
+		return fmt.Errorf("%w: the map can only be edited before the waves start", ErrWrongState)
+	}
+	w.Layout = w.Layout.editable()
+	w.State = EditorState
+	w.rebuildLayout()
+	return nil
+}
+
+// CloseEditor leaves the editor and starts a fresh match on the edited map,
+// or explains why the map can't be played
+func (w *World) CloseEditor() error {
+	if w.State != EditorState {
+		return fmt.Errorf("%w: the map editor is not open", ErrWrongState)
+	}
+	if err := w.MapProblem(); err != nil {
+		return err
+	}
+	w.reset()
+	return nil
+}
+
+// MapProblem reports why the map can't be played, or nil if it can. It
+// checks routes with the same search that tower placement uses.
+func (w *World) MapProblem() error {
+	if err := w.Layout.validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMap, err)
+	}
+	return nil
+}
+
+// PaintTerrain sets the ground of a cell on the map being edited. Painting
+// over a pre-built tower removes the tower.
+func (w *World) PaintTerrain(x, y int, terrain TerrainType) error {
+	if err := w.checkEditCell(x, y); err != nil {
+		return err
+	}
+	c, ok := terrainGrid[terrain]
+	if !ok {
+		return fmt.Errorf("terrain %d can't be painted", terrain)
+	}
+
+	row := []byte(w.Layout.Grid[y])
+	row[x] = byte(c)
+	w.Layout.Grid[y] = string(row)
+	w.Layout.removeTower(x, y)
+	w.rebuildLayout()
+	return nil
+}
+
+// MoveEntrance moves or resizes an entrance of the map being edited
+func (w *World) MoveEntrance(index int, span Span) error {
+	return w.moveSpan(w.Layout.Entrances, index, span)
+}
+
+// MoveExit moves or resizes an exit of the map being edited
+func (w *World) MoveExit(index int, span Span) error {
+	return w.moveSpan(w.Layout.Exits, index, span)
+}
+
+// moveSpan replaces one of the entrances or exits of the map being edited
+func (w *World) moveSpan(spans []Span, index int, span Span) error {
+	if w.State != EditorState {
+		return fmt.Errorf("%w: the map editor is not open", ErrWrongState)
+	}
+	if index < 0 || index >= len(spans) {
+		return fmt.Errorf("no entrance or exit %d", index+1)
+	}
+	if !w.Layout.fits(span) {
+		return fmt.Errorf("%w: span %d-%d on the %s edge", ErrOutOfBounds, span.Start, span.End, edgeNames[span.Edge])
+	}
+	spans[index] = span
+
+	// Towers can't stand on entrances or exits
+	for _, cell := range w.Map.SpanCells(span) {
+		w.Layout.removeTower(cell.X, cell.Y)
+	}
+	w.rebuildLayout()
+	return nil
+}
+
+// ToggleMapTower puts a pre-built tower on a cell of the map being edited,
+// or takes away the one already standing there
+func (w *World) ToggleMapTower(towerType TowerType, x, y int) error {
+	if err := w.checkEditCell(x, y); err != nil {
+		return err
+	}
+	if w.Layout.removeTower(x, y) {
+		w.rebuildLayout()
+		return nil
+	}
+
+	def := w.TowerDefFor(towerType)
+	if def == nil {
+		return fmt.Errorf("%w: %d", ErrUnknownTower, towerType)
+	}
+	if err := w.Map.checkCell(x, y); err != nil {
+		return fmt.Errorf("%w at %d,%d", err, x, y)
+	}
+	w.Layout.Towers = append(w.Layout.Towers, MapTower{Tower: def.ID, X: x, Y: y})
+	w.rebuildLayout()
+	return nil
+}
+
+// SetStartingMoney sets the money a match on the map being edited starts with
+func (w *World) SetStartingMoney(money int) error {
+	if w.State != EditorState {
+		return fmt.Errorf("%w: the map editor is not open", ErrWrongState)
+	}
+	w.Layout.Money = max(money, 1)
+	w.rebuildLayout()
+	return nil
+}
+
+// SetStartingLives sets the lives a match on the map being edited starts with
+func (w *World) SetStartingLives(lives int) error {
+	if w.State != EditorState {
+		return fmt.Errorf("%w: the map editor is not open", ErrWrongState)
+	}
+	w.Layout.Lives = max(lives, 1)
+	w.rebuildLayout()
+	return nil
+}
+
+// checkEditCell checks that a cell of the map can be edited now
+func (w *World) checkEditCell(x, y int) error {
+	if w.State != EditorState {
+		return fmt.Errorf("%w: the map editor is not open", ErrWrongState)
+	}
+	if x < 0 || x >= w.Layout.Width || y < 0 || y >= w.Layout.Height {
+		return fmt.Errorf("%w: %d,%d", ErrOutOfBounds, x, y)
+	}
+	return nil
+}
+
+// rebuildLayout shows the latest edits on the map, money and lives
+func (w *World) rebuildLayout() {
+	w.Map = w.Layout.Build(w.Defs)
+	w.Money = w.Layout.StartingMoney()
+	w.Lives = w.Layout.StartingLives()
+}
+
+// editable returns a copy of the map file that can be changed without
+// touching the original, with a grid row for every row of the map
+func (f *MapFile) editable() *MapFile {
+	edit := *f
+	edit.Entrances = append([]Span(nil), f.Entrances...)
+	edit.Exits = append([]Span(nil), f.Exits...)
+	edit.Routes = append([]Route(nil), f.Routes...)
+	edit.Towers = append([]MapTower(nil), f.Towers...)
+	edit.Grid = make([]string, f.Height)
+	for y := range edit.Grid {
+		if y < len(f.Grid) {
+			edit.Grid[y] = f.Grid[y]
+		} else {
+			edit.Grid[y] = strings.Repeat(string(terrainGrid[Empty]), f.Width)
+		}
+	}
+	return &edit
+}
+
+// removeTower takes the pre-built tower off a cell and reports whether
+// there was one
+func (f *MapFile) removeTower(x, y int) bool {
+	for i, placed := range f.Towers {
+		if placed.X == x && placed.Y == y {
+			f.Towers = append(f.Towers[:i], f.Towers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
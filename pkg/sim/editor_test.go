@@ -0,0 +1,101 @@
+package sim
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// editorWorld opens the editor on a small open map
+func editorWorld(t *testing.T) *World {
+	t.Helper()
+	w := NewWorldOnMap(1, gridLayout(t,
+		".....",
+		".....",
+		".....",
+	))
+	if err := w.OpenEditor(); err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestEditorValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(w *World) error
+		want string // Part of the reason the map can't be played
+	}{
+		{"no exit", func(w *World) error {
+			w.Layout.Exits = nil
+			return nil
+		}, "at least one entrance and one exit"},
+		{"walled off", func(w *World) error {
+			for y := range 3 {
+				if err := w.PaintTerrain(2, y, Rock); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, "a route has no path"},
+		{"across water", func(w *World) error {
+			for y := range 3 {
+				if err := w.PaintTerrain(2, y, Water); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, "a route has no path"},
+		{"spawn on rock", func(w *World) error {
+			return w.PaintTerrain(0, 2, Rock)
+		}, "span 2-2 on the left edge is blocked"},
+		{"entrance on the exit", func(w *World) error {
+			return w.MoveEntrance(0, Span{Edge: EdgeRight, Start: 2, End: 2})
+		}, "overlaps another"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := editorWorld(t)
+			if err := tt.edit(w); err != nil {
+				t.Fatal(err)
+			}
+			err := w.CloseEditor()
+			if !errors.Is(err, ErrInvalidMap) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got %v, want a map error with %q", err, tt.want)
+			}
+			if w.State != EditorState {
+				t.Error("editor closed on a map that can't be played")
+			}
+		})
+	}
+}
+
+func TestEditorEdits(t *testing.T) {
+	w := editorWorld(t)
+	if err := w.MoveEntrance(0, Span{Edge: EdgeLeft, Start: 0, End: 3}); !errors.Is(err, ErrOutOfBounds) {
+		t.Errorf("entrance off the edge gave %v", err)
+	}
+	if err := w.ToggleMapTower(DartTower, 0, 2); !errors.Is(err, ErrCellReserved) {
+		t.Errorf("tower on the entrance gave %v", err)
+	}
+	if err := w.PaintTerrain(5, 0, Mud); !errors.Is(err, ErrOutOfBounds) {
+		t.Errorf("painting off the map gave %v", err)
+	}
+
+	// Good edits carry over to the match the editor starts
+	if err := w.ToggleMapTower(DartTower, 2, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.PaintTerrain(1, 2, Mud); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.SetStartingMoney(500); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.CloseEditor(); err != nil {
+		t.Fatal(err)
+	}
+	if w.State != BuildState || w.Money != 500 || w.Map.GetTowerAt(2, 0) == nil || w.Map.Terrain[2][1] != Mud {
+		t.Error("the match didn't start on the edited map")
+	}
+}
@@ -0,0 +1,211 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// EffectStats are what a tower's shots do besides damage, as definition
+// files give them. A tier's stats are added to those of the tower.
+type EffectStats struct {
+	Chain      int     `json:"chain"`      // Extra enemies a shot jumps to from the one it hits
+	ChainRange float64 `json:"chainRange"` // Farthest a jump reaches, in cells
+	ChainKeep  float64 `json:"chainKeep"`  // Share of its damage a shot keeps on each jump, 0 to 1
+	Splash     float64 `json:"splash"`     // Radius around the impact that takes the damage too, in cells
+	BurnDamage float64 `json:"burnDamage"` // Damage per second of each burn stack
+	BurnTime   float64 `json:"burnTime"`   // Seconds a burn lasts after the last hit
+	BurnStacks int     `json:"burnStacks"` // Most burn stacks the tower's shots build up
+	Slow       float64 `json:"slow"`       // Share of an enemy's speed a hit takes away, 0 to 1
+	SlowTime   float64 `json:"slowTime"`   // Seconds a slow lasts
+	RootTime   float64 `json:"rootTime"`   // Seconds a hit holds an enemy in place; the slow starts when it ends
+}
+
+// add returns the sum of two sets of effect stats
+func (e EffectStats) add(other EffectStats) EffectStats {
+	return EffectStats{
+		Chain:      e.Chain + other.Chain,
+		ChainRange: e.ChainRange + other.ChainRange,
+		ChainKeep:  e.ChainKeep + other.ChainKeep,
+		Splash:     e.Splash + other.Splash,
+		BurnDamage: e.BurnDamage + other.BurnDamage,
+		BurnTime:   e.BurnTime + other.BurnTime,
+		BurnStacks: e.BurnStacks + other.BurnStacks,
+		Slow:       e.Slow + other.Slow,
+		SlowTime:   e.SlowTime + other.SlowTime,
+		RootTime:   e.RootTime + other.RootTime,
+	}
+}
+
+// negative reports whether any stat is below zero
+func (e EffectStats) negative() bool {
+	return e.Chain < 0 || e.ChainRange < 0 || e.ChainKeep < 0 || e.Splash < 0 ||
+		e.BurnDamage < 0 || e.BurnTime < 0 || e.BurnStacks < 0 ||
+		e.Slow < 0 || e.SlowTime < 0 || e.RootTime < 0
+}
+
+// validate checks that the stats of a tower at one level make sense together
+func (e EffectStats) validate() error {
+	switch {
+	case e.negative():
+		return fmt.Errorf("negative effect stats")
+	case e.Chain > 0 && e.ChainRange == 0:
+		return fmt.Errorf("chain needs a range")
+	case e.ChainKeep > 1:
+		return fmt.Errorf("chain keeps more than all of the damage")
+	case e.BurnDamage > 0 && (e.BurnTime == 0 || e.BurnStacks == 0):
+		return fmt.Errorf("burn needs a time and stacks")
+	case e.Slow > 1:
+		return fmt.Errorf("slow takes away more than all of the speed")
+	case e.Slow > 0 && e.SlowTime == 0:
+		return fmt.Errorf("slow needs a time")
+	}
+	return nil
+}
+
+// shot converts the stats to the units the simulation works in
+func (e EffectStats) shot(cellSize int) ShotEffects {
+	return ShotEffects{
+		Chain:      e.Chain,
+		ChainRange: e.ChainRange * float64(cellSize),
+		ChainKeep:  e.ChainKeep,
+		Splash:     e.Splash * float64(cellSize),
+		BurnDamage: e.BurnDamage,
+		BurnTicks:  secondsToTicks(e.BurnTime),
+		BurnStacks: e.BurnStacks,
+		Slow:       e.Slow,
+		SlowTicks:  secondsToTicks(e.SlowTime),
+		RootTicks:  secondsToTicks(e.RootTime),
+	}
+}
+
+// effectsAt returns the effect stats of a tower of this type at a level,
+// with the tiers it bought to get there added
+func (d *TowerDef) effectsAt(level int) EffectStats {
+	effects := d.EffectStats
+	for _, tier := range d.Tiers[:min(max(level-1, 0), len(d.Tiers))] {
+		effects = effects.add(tier.EffectStats)
+	}
+	return effects
+}
+
+// ShotEffects are what a tower's shots do besides damage, in pixels and
+// ticks. Towers carry them with their upgrades added, and each projectile
+// takes a copy when it is fired.
+type ShotEffects struct {
+	Chain      int     // Extra enemies a shot jumps to
+	ChainRange float64 // Farthest a jump reaches, in pixels
+	ChainKeep  float64 // Share of damage a shot keeps on each jump
+	Splash     float64 // Radius around the impact that takes the damage too, in pixels
+	BurnDamage float64 // Damage per second of each burn stack
+	BurnTicks  int     // How long a burn lasts after the last hit
+	BurnStacks int     // Most burn stacks the shots build up
+	Slow       float64 // Share of an enemy's speed a hit takes away
+	SlowTicks  int     // How long a slow lasts
+	RootTicks  int     // How long a hit holds an enemy in place
+}
+
+// chainShot carries a shot on from the enemy it hit to the nearest enemy
+// it hasn't hit yet, again and again, losing damage with each jump
+func (w *World) chainShot(proj *Projectile, first *Enemy) {
+	chain := []*Enemy{first}
+	damage := proj.GetDamage()
+	for jump := 0; jump < proj.Chain; jump++ {
+		damage *= proj.ChainKeep
+		if damage <= 0 {
+			break
+		}
+
+		from := chain[len(chain)-1]
+		var next *Enemy
+		nextDist := proj.ChainRange
+		for _, enemy := range w.Enemies {
+			if enemy == nil || enemy.Health <= 0 || enemy.CanFly && !proj.AntiAir || slices.Contains(chain, enemy) {
+				continue
+			}
+			if dist := math.Hypot(enemy.X-from.X, enemy.Y-from.Y); dist <= nextDist {
+				next, nextDist = enemy, dist
+			}
+		}
+		if next == nil {
+			break
+		}
+		w.damageEnemy(next, damage, proj.Source)
+		chain = append(chain, next)
+	}
+	if len(chain) > 1 {
+		w.Events.Publish(Event{Kind: EventChainLightning, Tower: proj.Source, Chain: chain})
+	}
+}
+
+// splashShot hurts and sets alight every enemy near where a shot landed,
+// apart from the one it hit directly
+func (w *World) splashShot(proj *Projectile, hit *Enemy) {
+	for _, enemy := range w.Enemies {
+		if enemy == nil || enemy == hit || enemy.Health <= 0 || enemy.CanFly && !proj.AntiAir {
+			continue
+		}
+		if math.Hypot(enemy.X-proj.X, enemy.Y-proj.Y) <= proj.Splash {
+			if proj.GetDamage() > 0 {
+				w.damageEnemy(enemy, proj.GetDamage(), proj.Source)
+			}
+			w.chillEnemy(enemy, proj)
+			igniteEnemy(enemy, proj)
+		}
+	}
+}
+
+// chillEnemy slows an enemy a shot hit, and roots it in place first if the
+// shot roots; the slow's time only runs once the root is over. The
+// strongest slow wins: one at least as strong as the slow on the enemy
+// replaces it and starts its time over, while a weaker one is ignored
+// until that wears off. A root only lands on an enemy that isn't chilled
+// already, so a freeze tower can't hold one still for good. Enemies shrug
+// off their SlowResist share of both.
+func (w *World) chillEnemy(enemy *Enemy, proj *Projectile) {
+	if proj.Slow <= 0 && proj.RootTicks == 0 {
+		return
+	}
+	resist := 0.0
+	if def := w.EnemyDefFor(enemy.Type); def != nil {
+		resist = def.SlowResist
+	}
+
+	if proj.RootTicks > 0 && !enemy.chilled() {
+		enemy.FrozenTimer = int(math.Round(float64(proj.RootTicks) * (1 - resist)))
+	}
+	if slow := proj.Slow * (1 - resist); slow > 0 && slow >= enemy.Slow {
+		enemy.Slow = slow
+		enemy.SlowTimer = proj.SlowTicks
+	}
+}
+
+// igniteEnemy adds a burn stack to an enemy a shot hit. Stacks build up to
+// the most the shot allows, but never drop below what a stronger tower
+// already built; the burn takes the hottest damage and the longest time
+// of the two, and the last tower to hit it is credited with the damage.
+func igniteEnemy(enemy *Enemy, proj *Projectile) {
+	if proj.BurnStacks == 0 || proj.BurnDamage <= 0 {
+		return
+	}
+	enemy.BurnStacks = max(enemy.BurnStacks, min(enemy.BurnStacks+1, proj.BurnStacks))
+	enemy.BurnDamage = math.Max(enemy.BurnDamage, proj.BurnDamage)
+	enemy.BurnTicks = max(enemy.BurnTicks, proj.BurnTicks)
+	enemy.BurnSource = proj.Source
+}
+
+// burnEnemy deals a tick's worth of burn damage to an enemy on fire
+func (w *World) burnEnemy(enemy *Enemy) {
+	if enemy.BurnTicks <= 0 {
+		return
+	}
+	enemy.BurnTicks--
+	if enemy.Health > 0 {
+		damage := float64(enemy.BurnStacks) * enemy.BurnDamage / TicksPerSecond
+		w.damageEnemy(enemy, damage, enemy.BurnSource)
+	}
+	if enemy.BurnTicks == 0 {
+		// Burnt out
+		enemy.BurnStacks, enemy.BurnDamage, enemy.BurnSource = 0, 0, nil
+	}
+}
@@ -0,0 +1,294 @@
+package sim
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// effectWorld is a match on an open map, ready for enemies to be set down
+func effectWorld(t *testing.T) *World {
+	t.Helper()
+	return NewWorldOnMap(1, gridLayout(t,
+		"............",
+		"............",
+		"............",
+		"............",
+	))
+}
+
+// spawnAt puts an enemy of a type with plenty of health on the field, at a
+// position counted in cells
+func spawnAt(w *World, enemyType EnemyType, x, y float64) *Enemy {
+	cellSize := float64(w.Map.CellSize)
+	e := NewEnemy(x*cellSize, y*cellSize, w.Map.CellSize, w.EnemyDefFor(enemyType), 1, w.EnemyDefFor(enemyType))
+	e.Health, e.MaxHealth = 100, 100
+	e.Next.X, e.Next.Y = w.Map.CellAt(e.X, e.Y)
+	e.TargetX, e.TargetY = e.X, e.Y
+	w.Enemies = append(w.Enemies, e)
+	return e
+}
+
+// shotAt is a projectile landing on an enemy, fired by a tower and with
+// its damage and effects
+func shotAt(target *Enemy, source *Tower, damage float64, effects ShotEffects) *Projectile {
+	return &Projectile{X: target.X, Y: target.Y, Damage: damage, Source: source, ShotEffects: effects}
+}
+
+// healthLost returns the damage each enemy has taken
+func healthLost(enemies ...*Enemy) []float64 {
+	lost := make([]float64, len(enemies))
+	for i, e := range enemies {
+		lost[i] = e.MaxHealth - e.Health
+	}
+	return lost
+}
+
+func TestChainShot(t *testing.T) {
+	effects := ShotEffects{Chain: 3, ChainRange: 15, ChainKeep: 0.5}
+
+	t.Run("nearest first, losing damage and stopping out of range", func(t *testing.T) {
+		w := effectWorld(t)
+		tower, _ := w.PlaceTower(LightningTower, 0, 0)
+		var chains [][]*Enemy
+		w.Events.Subscribe(EventChainLightning, func(e Event) { chains = append(chains, e.Chain) })
+
+		hit := spawnAt(w, SpiderEnemy, 1, 1)
+		flyer := spawnAt(w, HawkEnemy, 1.5, 1) // Nearest, but out of reach of a ground shot
+		near := spawnAt(w, SpiderEnemy, 2, 1)
+		next := spawnAt(w, SpiderEnemy, 3.2, 1)
+		far := spawnAt(w, SpiderEnemy, 5, 1) // Beyond ChainRange of the last jump
+
+		w.projectileHit(shotAt(hit, tower, 10, effects))
+		if got, want := healthLost(hit, flyer, near, next, far), []float64{10, 0, 5, 2.5, 0}; !slices.Equal(got, want) {
+			t.Errorf("damage taken %v, want %v", got, want)
+		}
+		if len(chains) != 1 || !slices.Equal(chains[0], []*Enemy{hit, near, next}) {
+			t.Errorf("chain events %v", chains)
+		}
+		if tower.DamageDealt != 17.5 {
+			t.Errorf("tower credited with %g damage, want 17.5", tower.DamageDealt)
+		}
+	})
+
+	t.Run("anti-air shots jump to flyers", func(t *testing.T) {
+		w := effectWorld(t)
+		hit := spawnAt(w, SpiderEnemy, 1, 1)
+		flyer := spawnAt(w, HawkEnemy, 1.5, 1)
+		near := spawnAt(w, SpiderEnemy, 2, 1)
+
+		proj := shotAt(hit, nil, 8, effects)
+		proj.AntiAir = true
+		w.projectileHit(proj)
+		if got, want := healthLost(hit, flyer, near), []float64{8, 4, 2}; !slices.Equal(got, want) {
+			t.Errorf("damage taken %v, want %v", got, want)
+		}
+	})
+
+	t.Run("no jumps with nobody in range", func(t *testing.T) {
+		w := effectWorld(t)
+		var chains int
+		w.Events.Subscribe(EventChainLightning, func(Event) { chains++ })
+		hit := spawnAt(w, SpiderEnemy, 1, 1)
+		spawnAt(w, SpiderEnemy, 4, 1)
+
+		w.projectileHit(shotAt(hit, nil, 10, effects))
+		if chains != 0 {
+			t.Error("a chain with no jumps was announced")
+		}
+	})
+}
+
+func TestSplashShot(t *testing.T) {
+	effects := ShotEffects{Splash: 10, BurnDamage: 1, BurnTicks: 60, BurnStacks: 3}
+
+	t.Run("around a hit", func(t *testing.T) {
+		w := effectWorld(t)
+		hit := spawnAt(w, SpiderEnemy, 2, 1)
+		near := spawnAt(w, SpiderEnemy, 2.8, 1)
+		far := spawnAt(w, SpiderEnemy, 3.5, 1)
+		flyer := spawnAt(w, HawkEnemy, 2, 1.5)
+
+		w.projectileHit(shotAt(hit, nil, 6, effects))
+		if got, want := healthLost(hit, near, far, flyer), []float64{6, 6, 0, 0}; !slices.Equal(got, want) {
+			t.Errorf("damage taken %v, want %v", got, want)
+		}
+		if hit.BurnStacks != 1 || near.BurnStacks != 1 || far.BurnStacks != 0 || flyer.BurnStacks != 0 {
+			t.Error("splash didn't set alight just the enemies it hurt")
+		}
+	})
+
+	t.Run("on a miss", func(t *testing.T) {
+		w := effectWorld(t)
+		near := spawnAt(w, SpiderEnemy, 5.5, 3)
+		proj := shotAt(near, nil, 6, effects)
+		proj.X -= near.Size/2 + 1 // Lands beside the enemy, just missing it
+		w.projectileHit(proj)
+		if lost := healthLost(near)[0]; lost != 6 || near.BurnStacks != 1 {
+			t.Errorf("missed shot took %g health and left %d burn stacks", lost, near.BurnStacks)
+		}
+	})
+}
+
+func TestIgniteEnemy(t *testing.T) {
+	w := effectWorld(t)
+	e := spawnAt(w, SpiderEnemy, 1, 1)
+	hot, _ := w.PlaceTower(FlameTower, 0, 0)
+	mild, _ := w.PlaceTower(FlameTower, 0, 2)
+	strong := &Projectile{Source: hot, ShotEffects: ShotEffects{BurnDamage: 2, BurnTicks: 60, BurnStacks: 3}}
+	weak := &Projectile{Source: mild, ShotEffects: ShotEffects{BurnDamage: 1, BurnTicks: 90, BurnStacks: 1}}
+
+	// Stacks build up one a hit, to the most the shot allows
+	for hit, want := range []int{1, 2, 3, 3} {
+		igniteEnemy(e, strong)
+		if e.BurnStacks != want {
+			t.Fatalf("hit %d left %d stacks, want %d", hit+1, e.BurnStacks, want)
+		}
+	}
+
+	// A weaker tower keeps the stacks, the hottest damage and the longest
+	// time, but takes the credit
+	igniteEnemy(e, weak)
+	if e.BurnStacks != 3 || e.BurnDamage != 2 || e.BurnTicks != 90 || e.BurnSource != mild {
+		t.Errorf("after a weaker hit: %d stacks of %g for %d ticks from %v", e.BurnStacks, e.BurnDamage, e.BurnTicks, e.BurnSource.Position)
+	}
+
+	// The burn runs its time out, then goes out completely
+	burnt := 3 * 2 * 90 / float64(TicksPerSecond)
+	for range 90 {
+		w.burnEnemy(e)
+	}
+	if lost := healthLost(e)[0]; !approx(lost, burnt) || !approx(mild.DamageDealt, lost) {
+		t.Errorf("burn took %g health and credited %g, want %g", lost, mild.DamageDealt, burnt)
+	}
+	if e.BurnTicks != 0 || e.BurnStacks != 0 || e.BurnDamage != 0 || e.BurnSource != nil {
+		t.Error("burn didn't go out")
+	}
+	w.burnEnemy(e)
+	if lost := healthLost(e)[0]; !approx(lost, burnt) {
+		t.Error("burn kept burning after going out")
+	}
+}
+
+func TestBurnKillsOnLastCell(t *testing.T) {
+	w := effectWorld(t)
+	w.State = PlayState
+	e := spawnAt(w, SpiderEnemy, 11.5, 3.5) // Standing on the exit
+	e.Health = 0.5
+	e.BurnStacks, e.BurnDamage, e.BurnTicks = 1, TicksPerSecond, 10 // A point a tick
+
+	var killed, leaked int
+	w.Events.Subscribe(EventEnemyKilled, func(Event) { killed++ })
+	w.Events.Subscribe(EventEnemyLeaked, func(Event) { leaked++ })
+	lives, money := w.Lives, w.Money
+	w.step()
+	if killed != 1 || leaked != 0 || w.Lives != lives || w.Money <= money {
+		t.Errorf("burnt to death on the exit: %d killed, %d leaked, %d lives left, %d money", killed, leaked, w.Lives, w.Money)
+	}
+	if slices.Contains(w.Enemies, e) {
+		t.Error("dead enemy is still on the field")
+	}
+}
+
+// approx reports whether two amounts of damage match but for rounding
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestChillEnemy(t *testing.T) {
+	w := effectWorld(t)
+	chill := func(e *Enemy, slow float64, slowTicks, rootTicks int) {
+		w.chillEnemy(e, &Projectile{ShotEffects: ShotEffects{Slow: slow, SlowTicks: slowTicks, RootTicks: rootTicks}})
+	}
+
+	t.Run("strongest slow wins", func(t *testing.T) {
+		e := spawnAt(w, SpiderEnemy, 1, 1)
+		chill(e, 0.4, 90, 0)
+		e.SlowTimer = 50
+		steps := []struct {
+			name      string
+			slow      float64
+			ticks     int
+			wantSlow  float64
+			wantTicks int
+		}{
+			{"weaker", 0.2, 90, 0.4, 50},
+			{"as strong", 0.4, 90, 0.4, 90},
+			{"stronger", 0.6, 30, 0.6, 30},
+		}
+		for _, step := range steps {
+			chill(e, step.slow, step.ticks, 0)
+			if e.Slow != step.wantSlow || e.SlowTimer != step.wantTicks {
+				t.Errorf("%s slow left %g for %d ticks, want %g for %d", step.name, e.Slow, e.SlowTimer, step.wantSlow, step.wantTicks)
+			}
+		}
+	})
+
+	t.Run("roots only land on enemies not chilled", func(t *testing.T) {
+		e := spawnAt(w, SpiderEnemy, 1, 1)
+		chill(e, 0.4, 90, 30)
+		if e.FrozenTimer != 30 || e.Slow != 0.4 {
+			t.Fatalf("first hit rooted for %d and slowed by %g", e.FrozenTimer, e.Slow)
+		}
+		e.FrozenTimer = 0
+		chill(e, 0.4, 90, 30)
+		if e.FrozenTimer != 0 {
+			t.Error("a slowed enemy was rooted again")
+		}
+		e.Slow, e.SlowTimer = 0, 0
+		chill(e, 0.4, 90, 30)
+		if e.FrozenTimer != 30 {
+			t.Error("an enemy whose slow wore off couldn't be rooted")
+		}
+	})
+
+	t.Run("bosses resist both", func(t *testing.T) {
+		e := spawnAt(w, BlobEnemy, 1, 1)
+		resist := w.EnemyDefFor(BlobEnemy).SlowResist
+		chill(e, 0.5, 90, 30)
+		if !approx(e.Slow, 0.5*(1-resist)) || e.FrozenTimer != int(math.Round(30*(1-resist))) || e.SlowTimer != 90 {
+			t.Errorf("boss slowed by %g and rooted for %d ticks with %g resist", e.Slow, e.FrozenTimer, resist)
+		}
+	})
+}
+
+func TestRootedEnemy(t *testing.T) {
+	t.Run("slow starts once the root ends", func(t *testing.T) {
+		w := effectWorld(t)
+		e := spawnAt(w, SpiderEnemy, 6.5, 1.5)
+		w.chillEnemy(e, &Projectile{ShotEffects: ShotEffects{Slow: 0.5, SlowTicks: 60, RootTicks: 30}})
+		x, y := e.X, e.Y
+		for range 30 {
+			e.Update(w)
+		}
+		if e.X != x || e.Y != y || e.SlowTimer != 60 {
+			t.Fatalf("after the root the enemy moved to %g,%g with %d slow ticks left", e.X, e.Y, e.SlowTimer)
+		}
+		e.Update(w)
+		if e.X == x && e.Y == y || e.SlowTimer != 59 {
+			t.Errorf("free of the root the enemy stayed at %g,%g with %d slow ticks left", e.X, e.Y, e.SlowTimer)
+		}
+	})
+
+	t.Run("still attacks", func(t *testing.T) {
+		w := effectWorld(t)
+		tower, err := w.PlaceTower(DartTower, 6, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := spawnAt(w, GhoulEnemy, 6.5, 1.5)
+		e.TargetTower, e.LastAttack = tower, 0
+		e.FrozenTimer = 10 * e.AttackRate
+		x, y := e.X, e.Y
+
+		for range 2 * e.AttackRate {
+			e.Update(w)
+		}
+		if tower.Health >= tower.MaxHealth {
+			t.Error("rooted ghoul didn't hurt the tower it was attacking")
+		}
+		if e.X != x || e.Y != y {
+			t.Error("rooted ghoul moved")
+		}
+	})
+}
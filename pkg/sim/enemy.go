@@ -0,0 +1,320 @@
+package sim
+
+import (
+	"image/color"
+	"math"
+)
+
+// EnemyType represents different types of enemies
+type EnemyType int
+
+const (
+	SpiderEnemy EnemyType = iota
+	SnakeEnemy
+	HawkEnemy  // Flies straight over the maze
+	GhoulEnemy // Will attack towers (renamed from Wolf)
+	BlobEnemy  // Boss type enemy
+)
+
+// Enemy represents an enemy unit
+type Enemy struct {
+	ID                int     // Tells the enemy apart from the others spawned in the match
+	X, Y              float64 // Precise position for smooth movement
+	TargetX, TargetY  float64 // Next target point
+	Speed             float64
+	Health            float64
+	MaxHealth         float64
+	Level             int     // Enemy level for health and rewards
+	Size              float64 // Size of the enemy for drawing
+	Type              EnemyType
+	PrimaryColor      color.RGBA // Sprite body color
+	SecondaryColor    color.RGBA // Sprite detail color
+	Route             int        // Index of the map route the enemy follows
+	Next              Point      // Cell the enemy is walking to
+	CanFly            bool       // Flies straight to the nearest exit; only anti-air towers can hit it
+	FrozenTimer       int        // Ticks the enemy stays rooted in place (0 if not rooted)
+	Slow              float64    // Share of its speed a slow takes away, 0 to 1
+	SlowTimer         int        // Ticks the slow lasts, counted once any root is over
+	BurnStacks        int        // Burn stacks on the enemy, 0 when not burning
+	BurnDamage        float64    // Damage per second of each burn stack
+	BurnTicks         int        // Ticks until the burn goes out
+	BurnSource        *Tower     `json:"-"` // Tower credited with the burn damage
+	CanAttack         bool       // Whether this enemy can attack towers
+	AttackDamage      float64    // How much damage this enemy does to towers
+	AttackRange       float64    // How close enemy needs to be to attack tower
+	AttackRate        int        // How often the enemy can attack (in ticks)
+	LastAttack        int        // Ticks until the next attack lands
+	AttackChance      float64    // Probability to choose to attack (0-1)
+	TargetTower       *Tower     `json:"-"` // Current tower being targeted
+	AttackDuration    int        // How long to stay in one place attacking (in ticks)
+	CurrentAttackTime int        // Ticks spent attacking so far
+	MoveTimer         float64    // Timer for movement animation
+	MoveOffset        float64    // Current movement offset
+}
+
+// NewEnemy creates a new enemy at a spawn position. Its stats come from
+// def and its colors from palette, the definition of the type leading the
+// current wave.
+func NewEnemy(startX, startY float64, cellSize int, def *EnemyDef, level int, palette *EnemyDef) *Enemy {
+	if def == nil || palette == nil {
+		return nil
+	}
+
+	startingHealth := def.HealthPerLevel * float64(level)
+
+	return &Enemy{
+		X:              startX,
+		Y:              startY,
+		TargetX:        startX,
+		TargetY:        startY,
+		Speed:          def.Speed,
+		Health:         startingHealth,
+		MaxHealth:      startingHealth,
+		Level:          level,
+		Size:           float64(cellSize) * def.Size,
+		Type:           def.Type,
+		PrimaryColor:   palette.PrimaryColor,
+		SecondaryColor: palette.SecondaryColor,
+		CanFly:         def.Flying,
+		CanAttack:      def.Attacks,
+		AttackDamage:   def.AttackDamage,
+		AttackRange:    float64(cellSize) * def.AttackRange,
+		AttackRate:     secondsToTicks(def.AttackInterval),
+		AttackDuration: secondsToTicks(def.AttackDuration),
+		AttackChance:   def.AttackChance,
+	}
+}
+
+// Update updates the enemy position and handles pathfinding
+func (e *Enemy) Update(w *World) bool {
+	gameMap := w.Map
+
+	// A rooted enemy stands still but still fights the towers around it.
+	// Its slow only starts to wear off once the root has.
+	if e.FrozenTimer > 0 {
+		e.FrozenTimer--
+	} else {
+		if e.SlowTimer > 0 {
+			e.SlowTimer--
+			if e.SlowTimer == 0 {
+				e.Slow = 0
+			}
+		}
+		if !e.move(gameMap) {
+			return false
+		}
+	}
+
+	// Calculate grid position
+	gridX, gridY := gameMap.CellAt(e.X, e.Y)
+
+	// Check if we're too close to entrance to allow attacks
+	if gameMap.EntranceDepth(gridX, gridY) < 4 { // No attacks in first 4 squares
+		e.TargetTower = nil // Clear any existing target
+		return e.atExit(gameMap, gridX, gridY)
+	}
+
+	// If enemy can attack and has no target, look for towers to attack
+	if e.CanAttack && e.TargetTower == nil {
+		// Random roll to decide if we look for a tower to attack
+		if w.Rand.Float64() < e.AttackChance {
+			// Get nearby towers and check if any are available for attack
+			towers := gameMap.GetTowersInRange(e.X, e.Y, e.AttackRange)
+			availableTowers := make([]*Tower, 0)
+
+			// Filter out towers that are already being attacked
+			for _, t := range towers {
+				isBeingAttacked := false
+				for _, other := range w.Enemies {
+					if other != nil && other != e && other.TargetTower == t && other.CurrentAttackTime > 0 {
+						isBeingAttacked = true
+						break
+					}
+				}
+				if !isBeingAttacked {
+					availableTowers = append(availableTowers, t)
+				}
+			}
+
+			// Only attack if there are available towers
+			if len(availableTowers) > 0 {
+				// Randomly select one tower to attack
+				e.TargetTower = availableTowers[w.Rand.Intn(len(availableTowers))]
+				e.LastAttack = e.AttackRate // Start with full cooldown
+			}
+		}
+	}
+
+	// If we have a target tower, try to attack it
+	if e.TargetTower != nil {
+		// First verify tower still exists
+		towerStillExists := false
+		for _, t := range gameMap.Towers {
+			if t == e.TargetTower {
+				towerStillExists = true
+				break
+			}
+		}
+		if !towerStillExists {
+			e.TargetTower = nil
+			e.CurrentAttackTime = 0
+			return false
+		}
+
+		// Don't attack towers in first 4 squares
+		if gameMap.EntranceDepth(e.TargetTower.Position.X, e.TargetTower.Position.Y) < 4 {
+			e.TargetTower = nil
+			e.CurrentAttackTime = 0
+			return false
+		}
+
+		// Calculate distance to tower
+		towerX, towerY := gameMap.CellCenter(e.TargetTower.Position.X, e.TargetTower.Position.Y)
+		dx := towerX - e.X
+		dy := towerY - e.Y
+		dist := math.Sqrt(dx*dx + dy*dy)
+
+		if dist <= e.AttackRange {
+			// In range, handle attack sequence
+			if e.CurrentAttackTime < e.AttackDuration {
+				// Still in attack animation
+				e.CurrentAttackTime++
+
+				// Only deal damage on specific intervals
+				if e.LastAttack <= 0 {
+					// Attack the tower
+					if e.TargetTower.TakeDamage(e.AttackDamage) {
+						// Tower was destroyed
+						w.Map.RemoveTower(e.TargetTower.Position.X, e.TargetTower.Position.Y)
+						w.Events.Publish(Event{Kind: EventTowerDestroyed, Tower: e.TargetTower, Enemy: e})
+						e.TargetTower = nil
+						e.CurrentAttackTime = 0
+						e.LastAttack = e.AttackRate
+					} else {
+						// Continue attack sequence
+						w.Events.Publish(Event{Kind: EventTowerDamaged, Tower: e.TargetTower, Enemy: e, Damage: e.AttackDamage})
+						e.LastAttack = e.AttackRate
+					}
+				} else {
+					e.LastAttack--
+				}
+				return false // Stay in place while attacking
+			} else {
+				// Attack sequence complete
+				e.TargetTower = nil
+				e.CurrentAttackTime = 0
+				e.LastAttack = e.AttackRate
+			}
+		} else {
+			// Tower out of range, stop attacking
+			e.TargetTower = nil
+			e.CurrentAttackTime = 0
+			e.LastAttack = e.AttackRate
+		}
+	}
+
+	return e.atExit(gameMap, gridX, gridY)
+}
+
+// move walks the enemy one tick along its way. It reports false when the
+// enemy's route is cut off and it can't go anywhere.
+func (e *Enemy) move(gameMap *GameMap) bool {
+	// Flyers ignore the maze and head straight for the nearest exit. Ground
+	// units pick the next cell on reaching the center of one, or sooner if
+	// a tower went up on the way.
+	cellX, cellY := gameMap.CellAt(e.X, e.Y)
+	if e.CanFly {
+		if e.X == e.TargetX && e.Y == e.TargetY {
+			e.Next = gameMap.NearestExit(e.X, e.Y)
+			e.TargetX, e.TargetY = gameMap.CellCenter(e.Next.X, e.Next.Y)
+		}
+	} else if (e.X == e.TargetX && e.Y == e.TargetY) || !gameMap.Passable(e.Next.X, e.Next.Y, false) {
+		next, ok := gameMap.nextStep(e.Route, cellX, cellY, e.pathProfile(gameMap.defs))
+		if !ok {
+			return false
+		}
+		e.Next = next
+		e.TargetX, e.TargetY = gameMap.CellCenter(next.X, next.Y)
+	}
+
+	// Move towards current target
+	dx := e.TargetX - e.X
+	dy := e.TargetY - e.Y
+	dist := math.Sqrt(dx*dx + dy*dy)
+
+	// The ground underfoot and any slow on us hold us back
+	speed := e.currentSpeed(gameMap)
+	if dist < speed {
+		// Reached target point
+		e.X = e.TargetX
+		e.Y = e.TargetY
+	} else {
+		// Move towards target
+		e.X += (dx / dist) * speed
+		e.Y += (dy / dist) * speed
+
+		// Update movement animation
+		e.MoveTimer += 0.05 // Slower animation
+		if e.MoveTimer > 2*math.Pi {
+			e.MoveTimer -= 2 * math.Pi
+		}
+		// Sway according to the enemy type's walking animation
+		def := gameMap.defs.enemy(e.Type)
+		e.MoveOffset = math.Sin(e.MoveTimer*def.BobSpeed) * def.BobHeight
+	}
+	return true
+}
+
+// atExit reports whether the enemy has reached the center of a cell of its
+// route's exit. Flyers leave through whichever exit they reach.
+func (e *Enemy) atExit(gameMap *GameMap, gridX, gridY int) bool {
+	if e.CanFly && !gameMap.IsExit(gridX, gridY) || !e.CanFly && !gameMap.IsRouteExit(e.Route, gridX, gridY) {
+		return false
+	}
+
+	// Must be close to the center of the exit cell
+	cellCenterX, cellCenterY := gameMap.CellCenter(gridX, gridY)
+
+	dx := e.X - cellCenterX
+	dy := e.Y - cellCenterY
+	distanceToCenter := math.Sqrt(dx*dx + dy*dy)
+
+	// Only count as reached if very close to center
+	return distanceToCenter < 5.0
+}
+
+// currentSpeed returns how far the enemy moves this tick, held back by the
+// ground underfoot and any slow on it, or 0 while it is rooted
+func (e *Enemy) currentSpeed(gameMap *GameMap) float64 {
+	if e.FrozenTimer > 0 {
+		return 0
+	}
+	cellX, cellY := gameMap.CellAt(e.X, e.Y)
+	return e.Speed * gameMap.SpeedFactor(cellX, cellY, e.CanFly) * (1 - e.Slow)
+}
+
+// remaining returns what the rest of the enemy's way to its exit costs, in
+// cells of open ground: the flow field cost of the cell it is walking to,
+// weighed by its own path costs, plus the way to that cell. Flyers head
+// straight for the cell of their exit. An enemy that is cut off has
+// nowhere left to go, so it counts as furthest from the exit.
+func (e *Enemy) remaining(gameMap *GameMap) float64 {
+	nextX, nextY := gameMap.CellCenter(e.Next.X, e.Next.Y)
+	toNext := math.Hypot(nextX-e.X, nextY-e.Y) / float64(gameMap.CellSize)
+	if e.CanFly {
+		return toNext
+	}
+	if e.Next.X < 0 || e.Next.X >= gameMap.Width || e.Next.Y < 0 || e.Next.Y >= gameMap.Height {
+		return math.Inf(1)
+	}
+	cost := gameMap.flowField(gameMap.Routes[e.Route].Exit-1, e.pathProfile(gameMap.defs))[e.Next.Y][e.Next.X]
+	if cost < 0 {
+		return math.Inf(1)
+	}
+	return float64(cost)/costScale + toNext
+}
+
+// chilled reports whether the enemy is slowed or rooted
+func (e *Enemy) chilled() bool {
+	return e.SlowTimer > 0 || e.FrozenTimer > 0
+}
@@ -0,0 +1,129 @@
+package sim
+
+import (
+	_ "embed"
+	"fmt"
+	"image/color"
+	"math"
+	"os"
+)
+
+//go:embed data/enemies.json
+var builtinEnemyDefs []byte
+
+// EnemyDef holds the stats and look of an enemy type
+type EnemyDef struct {
+	Type           EnemyType  `json:"-"`
+	ID             string     `json:"id"`             // Name used in definition files
+	Name           string     `json:"name"`           // Name shown to the player
+	Speed          float64    `json:"speed"`          // Pixels moved per tick
+	HealthPerLevel float64    `json:"healthPerLevel"` // Hit points gained per enemy level
+	Size           float64    `json:"size"`           // Size as a fraction of a cell
+	Flying         bool       `json:"flying"`         // Whether it flies over obstacles
+	Boss           bool       `json:"boss"`           // Only spawned on boss waves
+	Reward         int        `json:"reward"`         // Points per enemy level when killed
+	KillScore      int        `json:"killScore"`      // Bonus score when killed
+	Attacks        bool       `json:"attacks"`        // Whether it attacks towers
+	AttackDamage   float64    `json:"attackDamage"`   // Damage per hit on a tower
+	AttackRange    float64    `json:"attackRange"`    // Attack range in cells
+	AttackInterval float64    `json:"attackInterval"` // Seconds between hits
+	AttackDuration float64    `json:"attackDuration"` // Seconds spent attacking before moving on
+	AttackChance   float64    `json:"attackChance"`   // Chance (0-1) to pick a tower to attack each tick
+	Sprite         string     `json:"sprite"`         // Name of the pixel art drawn for the enemy
+	PrimaryColor   color.RGBA `json:"primaryColor"`   // Body color for waves led by this type
+	SecondaryColor color.RGBA `json:"secondaryColor"` // Detail color for waves led by this type
+	BobSpeed       float64    `json:"bobSpeed"`       // Speed of the walking animation
+	BobHeight      float64    `json:"bobHeight"`      // Pixels the walking animation sways
+	PathCosts      PathCosts  `json:"pathCosts"`      // How it weighs cells when picking a path
+	SlowResist     float64    `json:"slowResist"`     // Share of slows and roots it shrugs off, 0 to 1
+}
+
+// PathCosts weigh the cells an enemy type walks through when it picks a
+// path, relative to open ground. A cell that is both near a tower and
+// covered by a freeze tower pays both. Unset costs use the defaults.
+type PathCosts struct {
+	Mud        float64 `json:"mud,omitempty"`        // Cost of a mud cell; 0 uses MudCost
+	NearTower  float64 `json:"nearTower,omitempty"`  // Multiplier for cells next to a tower; below 1 draws the enemy to towers
+	FreezeZone float64 `json:"freezeZone,omitempty"` // Multiplier for cells in range of a freeze tower
+}
+
+// enemyIDs maps definition file ids to enemy types
+var enemyIDs = map[string]EnemyType{
+	"spider": SpiderEnemy,
+	"snake":  SnakeEnemy,
+	"hawk":   HawkEnemy,
+	"ghoul":  GhoulEnemy,
+	"blob":   BlobEnemy,
+}
+
+// enemyDefs holds the definition of every enemy type new matches are
+// played with, indexed by type
+var enemyDefs []*EnemyDef
+
+func init() {
+	defs, err := parseEnemyDefs(builtinEnemyDefs, nil)
+	if err != nil {
+		panic(fmt.Sprintf("built-in enemy definitions: %v", err))
+	}
+	enemyDefs = defs
+}
+
+// LoadEnemyDefs rebalances enemies from a file on disk, the same way
+// LoadTowerDefs does for towers
+func LoadEnemyDefs(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	defs, err := parseEnemyDefs(data, enemyDefs)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	enemyDefs = defs
+	return nil
+}
+
+// parseEnemyDefs applies a definition table on top of existing definitions
+func parseEnemyDefs(data []byte, base []*EnemyDef) ([]*EnemyDef, error) {
+	defs, err := parseDefs(data, base, enemyIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, def := range defs {
+		if def == nil {
+			continue
+		}
+		def.Type = EnemyType(i)
+		if err := def.validate(); err != nil {
+			return nil, fmt.Errorf("enemy %q: %w", def.ID, err)
+		}
+	}
+	return defs, nil
+}
+
+// validate checks a definition for values the simulation can't use
+func (d *EnemyDef) validate() error {
+	switch {
+	case d.Speed <= 0:
+		return fmt.Errorf("speed must be positive")
+	case d.HealthPerLevel <= 0:
+		return fmt.Errorf("health must be positive")
+	case d.Size <= 0:
+		return fmt.Errorf("size must be positive")
+	case d.AttackChance < 0 || d.AttackChance > 1:
+		return fmt.Errorf("attack chance must be between 0 and 1")
+	case d.Attacks && d.AttackInterval <= 0:
+		return fmt.Errorf("attack interval must be positive")
+	case d.PathCosts.Mud < 0 || d.PathCosts.NearTower < 0 || d.PathCosts.FreezeZone < 0:
+		return fmt.Errorf("path costs can't be negative")
+	case d.SlowResist < 0 || d.SlowResist > 1:
+		return fmt.Errorf("slow resist must be between 0 and 1")
+	}
+	return nil
+}
+
+// secondsToTicks converts a duration from a definition file into ticks
+func secondsToTicks(seconds float64) int {
+	return int(math.Round(seconds * TicksPerSecond))
+}
@@ -0,0 +1,68 @@
+package sim
+
+// EventKind identifies something that happened during a tick
+type EventKind int
+
+const (
+	EventEnemySpawned   EventKind = iota // An enemy entered the map
+	EventEnemyDamaged                    // A projectile hurt an enemy; Tower is the one that fired it
+	EventEnemyKilled                     // An enemy died; Points holds the reward
+	EventEnemyLeaked                     // An enemy reached the exit and cost a life
+	EventTowerPlaced                     // A tower was built; Points holds the price
+	EventTowerSold                       // A tower was sold; Points holds the refund
+	EventTowerUpgraded                   // A tower bought its next tier; Points holds the price
+	EventTowerFired                      // A tower launched a projectile
+	EventTowerDamaged                    // An enemy hit a tower without destroying it
+	EventTowerDestroyed                  // An enemy destroyed a tower
+	EventWaveStarted                     // A wave began spawning
+	EventWaveEnded                       // Every enemy of a wave is gone
+	EventGameOver                        // The last life was lost
+	EventChainLightning                  // A shot jumped on from the enemy it hit; Chain lists the enemies in order
+)
+
+// Event describes a single thing that happened in the simulation. Fields
+// that don't apply to the kind are left empty.
+type Event struct {
+	Kind   EventKind
+	Enemy  *Enemy   // Enemy involved, if any
+	Tower  *Tower   // Tower involved, if any
+	Damage float64  // Damage dealt for damage events
+	Points int      // Points gained or spent
+	Wave   int      // Wave number for wave events, counting from 0
+	Chain  []*Enemy // Enemies a chained shot hit, in order
+}
+
+// EventBus delivers simulation events to the handlers subscribed to them.
+// Handlers run synchronously inside World.Tick and must not modify the world.
+type EventBus struct {
+	handlers map[EventKind][]func(Event)
+	all      []func(Event)
+}
+
+// NewEventBus creates an event bus with no subscribers
+func NewEventBus() *EventBus {
+	return &EventBus{handlers: make(map[EventKind][]func(Event))}
+}
+
+// Subscribe registers a handler for one kind of event
+func (b *EventBus) Subscribe(kind EventKind, handler func(Event)) {
+	b.handlers[kind] = append(b.handlers[kind], handler)
+}
+
+// SubscribeAll registers a handler for every event
+func (b *EventBus) SubscribeAll(handler func(Event)) {
+	b.all = append(b.all, handler)
+}
+
+// Publish sends an event to its subscribers. A nil bus drops it.
+func (b *EventBus) Publish(e Event) {
+	if b == nil {
+		return
+	}
+	for _, handler := range b.handlers[e.Kind] {
+		handler(e)
+	}
+	for _, handler := range b.all {
+		handler(e)
+	}
+}
@@ -0,0 +1,302 @@
+package sim
+
+import (
+	"math"
+	"slices"
+)
+
+// steps are the directions units move in, in the order ties are broken:
+// right, down, up, left (prioritize moving right)
+var steps = [4]Point{{1, 0}, {0, 1}, {0, -1}, {-1, 0}}
+
+// costScale is what a cell of open ground costs in a flow field. Costs are
+// whole numbers, so the scale leaves room for cells that cost less.
+const costScale = 10
+
+// pathProfile is what decides how a unit weighs its way across the map
+type pathProfile struct {
+	flying bool
+	costs  PathCosts
+}
+
+// groundProfile is the profile of a ground unit with default costs
+var groundProfile = pathProfile{}
+
+// pathProfile returns the profile the enemy picks its path with under the
+// given definitions
+func (e *Enemy) pathProfile(defs *Defs) pathProfile {
+	profile := pathProfile{flying: e.CanFly}
+	if def := defs.enemy(e.Type); def != nil {
+		profile.costs = def.PathCosts
+	}
+	return profile
+}
+
+// flowKey identifies one flow field: the exit it leads to and the profile
+// it weighs cells with
+type flowKey struct {
+	exit    int
+	profile pathProfile
+}
+
+// costMap prices the cells of a map for one path profile
+type costMap struct {
+	m       *GameMap
+	profile pathProfile
+	near    [][]bool // Cells next to a tower
+	frozen  [][]bool // Cells in range of a freeze tower
+}
+
+// costMap works out what every cell costs a unit with the given profile
+func (m *GameMap) costMap(profile pathProfile) *costMap {
+	c := &costMap{m: m, profile: profile}
+	if profile.costs.NearTower > 0 {
+		c.near = m.towerCover(func(t *Tower, x, y int) bool {
+			return abs(t.Position.X-x) <= 1 && abs(t.Position.Y-y) <= 1
+		})
+	}
+	if profile.costs.FreezeZone > 0 {
+		c.frozen = m.towerCover(func(t *Tower, x, y int) bool {
+			def := m.defs.tower(t.Type)
+			if def == nil || def.ProjType != FreezeProjectile {
+				return false
+			}
+			towerX, towerY := m.CellCenter(t.Position.X, t.Position.Y)
+			cellX, cellY := m.CellCenter(x, y)
+			return math.Hypot(cellX-towerX, cellY-towerY) <= t.AttackRange
+		})
+	}
+	return c
+}
+
+// towerCover marks the cells that any tower covers
+func (m *GameMap) towerCover(covers func(t *Tower, x, y int) bool) [][]bool {
+	cover := make([][]bool, m.Height)
+	for y := range cover {
+		cover[y] = make([]bool, m.Width)
+		for x := range cover[y] {
+			for _, tower := range m.Towers {
+				if covers(tower, x, y) {
+					cover[y][x] = true
+					break
+				}
+			}
+		}
+	}
+	return cover
+}
+
+// cost returns what stepping onto a cell costs
+func (c *costMap) cost(x, y int) int {
+	costs := c.profile.costs
+	cost := 1.0
+	if !c.profile.flying && c.m.Terrain[y][x] == Mud {
+		cost = MudCost
+		if costs.Mud > 0 {
+			cost = costs.Mud
+		}
+	}
+	if c.near != nil && c.near[y][x] {
+		cost *= costs.NearTower
+	}
+	if c.frozen != nil && c.frozen[y][x] {
+		cost *= costs.FreezeZone
+	}
+	return max(1, int(math.Round(cost*costScale)))
+}
+
+// flowField returns the cost of the cheapest path from every cell to an
+// exit, counting the cell itself, or -1 for cells with no path. Fields are
+// built on first use and kept until the terrain changes, so every enemy
+// heading for the same exit with the same profile shares one search.
+func (m *GameMap) flowField(exit int, profile pathProfile) [][]int {
+	key := flowKey{exit, profile}
+	if field, ok := m.flow[key]; ok {
+		return field
+	}
+	if m.flow == nil {
+		m.flow = make(map[flowKey][][]int)
+	}
+	field := m.buildFlowField(m.Exits[exit], m.costMap(profile))
+	m.flow[key] = field
+	return field
+}
+
+// buildFlowField searches outwards from every cell of an exit at once, so
+// each cell ends up with the cost of the cheapest path from it to the exit.
+// It is Dijkstra's search run backwards from the goal, which gives every
+// cell the path a weighted A* search from that cell would find.
+func (m *GameMap) buildFlowField(exit Span, costs *costMap) [][]int {
+	flying := costs.profile.flying
+	field := make([][]int, m.Height)
+	for y := range field {
+		field[y] = make([]int, m.Width)
+		for x := range field[y] {
+			field[y][x] = -1 // Not reached yet
+		}
+	}
+
+	// Cells waiting to be expanded, bucketed by their cost
+	var buckets [][]Point
+	push := func(cell Point, cost int) {
+		field[cell.Y][cell.X] = cost
+		for len(buckets) <= cost {
+			buckets = append(buckets, nil)
+		}
+		buckets[cost] = append(buckets[cost], cell)
+	}
+	for _, cell := range m.SpanCells(exit) {
+		if m.Passable(cell.X, cell.Y, flying) {
+			push(cell, costs.cost(cell.X, cell.Y))
+		}
+	}
+
+	// Expand the cheapest cells first
+	for reached := 0; reached < len(buckets); reached++ {
+		for _, current := range buckets[reached] {
+			// Skip cells a cheaper path has reached since
+			if field[current.Y][current.X] < reached {
+				continue
+			}
+
+			for _, step := range steps {
+				next := Point{current.X + step.X, current.Y + step.Y}
+				if !m.Passable(next.X, next.Y, flying) {
+					continue
+				}
+				nextCost := reached + costs.cost(next.X, next.Y)
+				if known := field[next.Y][next.X]; known >= 0 && known <= nextCost {
+					continue
+				}
+				push(next, nextCost)
+			}
+		}
+	}
+	return field
+}
+
+// terrainChanged drops the flow fields and trial paths after the terrain
+// has changed
+func (m *GameMap) terrainChanged() {
+	m.flow = nil
+	m.trial = nil
+}
+
+// nextStep returns the cell a unit on a route should walk to from the cell
+// it is on, following the flow field downhill. A unit on its exit stays on
+// the cell so it can walk to the center. It reports false when the exit
+// can't be reached.
+func (m *GameMap) nextStep(route, x, y int, profile pathProfile) (Point, bool) {
+	if m.IsRouteExit(route, x, y) {
+		return Point{x, y}, true
+	}
+
+	field := m.flowField(m.Routes[route].Exit-1, profile)
+	best, next := -1, Point{}
+	for _, step := range steps {
+		cell := Point{x + step.X, y + step.Y}
+		if !m.Passable(cell.X, cell.Y, profile.flying) || field[cell.Y][cell.X] < 0 {
+			continue
+		}
+		if cost := field[cell.Y][cell.X]; best < 0 || cost < best {
+			best, next = cost, cell
+		}
+	}
+	return next, best >= 0
+}
+
+// PathGroup is the path along each route that enemies of some types walk
+type PathGroup struct {
+	Types []EnemyType // Enemy types walking these paths, in the order asked for
+	Paths [][]Point   // Cells walked along each route; nil for a route that is cut off
+}
+
+// trialPaths is an answer of RoutePathsWithTower, kept until the terrain
+// changes so hovering over one cell doesn't search the map every frame
+type trialPaths struct {
+	towerType TowerType
+	cell      Point
+	types     []EnemyType
+	groups    []PathGroup
+}
+
+// RoutePaths returns the cells ground enemies of the given types walk
+// through on each route, from the entrance cell with the cheapest way out
+// to the exit. Each type weighs the map with its own path costs; types
+// that end up walking the same paths share a group. Flyers ignore the
+// paths and are left out.
+func (m *GameMap) RoutePaths(types []EnemyType) []PathGroup {
+	var groups []PathGroup
+	for _, enemyType := range types {
+		def := m.defs.enemy(enemyType)
+		if def == nil || def.Flying {
+			continue
+		}
+		profile := pathProfile{costs: def.PathCosts}
+		paths := make([][]Point, len(m.Routes))
+		for route := range m.Routes {
+			paths[route] = m.routePath(route, profile)
+		}
+
+		shared := slices.IndexFunc(groups, func(group PathGroup) bool {
+			return slices.EqualFunc(group.Paths, paths, slices.Equal)
+		})
+		if shared >= 0 {
+			groups[shared].Types = append(groups[shared].Types, enemyType)
+		} else {
+			groups = append(groups, PathGroup{Types: []EnemyType{enemyType}, Paths: paths})
+		}
+	}
+	return groups
+}
+
+// RoutePathsWithTower returns the paths RoutePaths would return with a
+// tower of a type on a cell, without changing the map. The tower counts
+// for the enemies that steer around towers or freeze range too.
+func (m *GameMap) RoutePathsWithTower(towerType TowerType, x, y int, types []EnemyType) []PathGroup {
+	cell := Point{x, y}
+	if t := m.trial; t != nil && t.towerType == towerType && t.cell == cell && slices.Equal(t.types, types) {
+		return t.groups
+	}
+
+	trial := *m
+	trial.flow, trial.trial = nil, nil
+	trial.Terrain = make([][]TerrainType, len(m.Terrain))
+	for row := range m.Terrain {
+		trial.Terrain[row] = append([]TerrainType(nil), m.Terrain[row]...)
+	}
+	trial.Towers = slices.Clip(m.Towers)
+	if x >= 0 && x < m.Width && y >= 0 && y < m.Height && m.defs.tower(towerType) != nil {
+		trial.buildTower(towerType, x, y)
+	}
+
+	groups := trial.RoutePaths(types)
+	m.trial = &trialPaths{towerType: towerType, cell: cell, types: slices.Clone(types), groups: groups}
+	return groups
+}
+
+// routePath follows a profile's flow field of a route from its entrance to
+// its exit
+func (m *GameMap) routePath(route int, profile pathProfile) []Point {
+	field := m.flowField(m.Routes[route].Exit-1, profile)
+	start, best := Point{}, -1
+	for _, cell := range m.SpanCells(m.RouteEntrance(route)) {
+		if cost := field[cell.Y][cell.X]; cost >= 0 && (best < 0 || cost < best) {
+			start, best = cell, cost
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+
+	path := []Point{start}
+	for cell := start; !m.IsRouteExit(route, cell.X, cell.Y); {
+		next, ok := m.nextStep(route, cell.X, cell.Y, profile)
+		if !ok || len(path) > m.Width*m.Height {
+			return nil
+		}
+		path = append(path, next)
+		cell = next
+	}
+	return path
+}
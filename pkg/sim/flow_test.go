@@ -0,0 +1,213 @@
+package sim
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// benchEnemies is how many enemies reroute in each benchmark iteration
+const benchEnemies = 200
+
+// benchMap builds a large map of open ground with walls that force a
+// winding path, and the cells its enemies stand on
+func benchMap(tb testing.TB) (*GameMap, []Point) {
+	const width, height = 60, 40
+	grid := make([]string, height)
+	for y := range grid {
+		row := []byte(strings.Repeat(".", width))
+		for x := 6; x < width-6; x += 6 {
+			// Walls hang from the top and bottom in turn
+			if (x/6)%2 == 0 && y < height-3 || (x/6)%2 == 1 && y > 2 {
+				row[x] = '#'
+			}
+		}
+		grid[y] = string(row)
+	}
+	layout := &MapFile{
+		Name:      "Bench",
+		Width:     width,
+		Height:    height,
+		CellSize:  16,
+		Entrances: []Span{{Edge: EdgeLeft, Start: 18, End: 21}},
+		Exits:     []Span{{Edge: EdgeRight, Start: 18, End: 21}},
+		Grid:      grid,
+	}
+	if err := layout.validate(); err != nil {
+		tb.Fatal(err)
+	}
+
+	m := layout.Build(currentDefs())
+	cells := make([]Point, 0, benchEnemies)
+	for i := 0; len(cells) < benchEnemies; i++ {
+		cell := Point{(i * 7) % width, (i * 13) % height}
+		if !m.IsBlocked(cell.X, cell.Y) {
+			cells = append(cells, cell)
+		}
+	}
+	return m, cells
+}
+
+// bfsPath is the search every ground enemy used to run on its own: a
+// breadth-first search from the enemy's cell that stops at the first exit
+// cell of its route, trying right, down, up and left in turn
+func bfsPath(m *GameMap, route int, from Point) ([]Point, bool) {
+	visited := make([][]bool, m.Height)
+	parent := make([][]Point, m.Height)
+	for y := range visited {
+		visited[y] = make([]bool, m.Width)
+		parent[y] = make([]Point, m.Width)
+	}
+
+	queue := []Point{from}
+	visited[from.Y][from.X] = true
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if m.IsRouteExit(route, current.X, current.Y) {
+			var path []Point
+			for current != from {
+				path = append([]Point{current}, path...)
+				current = parent[current.Y][current.X]
+			}
+			return path, true
+		}
+		for _, step := range steps {
+			next := Point{current.X + step.X, current.Y + step.Y}
+			if m.Passable(next.X, next.Y, false) && !visited[next.Y][next.X] {
+				visited[next.Y][next.X] = true
+				parent[next.Y][next.X] = current
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil, false
+}
+
+// BenchmarkRerouteBFS reroutes every enemy after a tower goes up the old
+// way, with one search per enemy
+func BenchmarkRerouteBFS(b *testing.B) {
+	m, cells := benchMap(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, cell := range cells {
+			if _, ok := bfsPath(m, 0, cell); !ok {
+				b.Fatal("no path")
+			}
+		}
+	}
+}
+
+// BenchmarkRerouteFlowField reroutes every enemy after a tower goes up by
+// rebuilding the shared flow field once and stepping each enemy along it
+func BenchmarkRerouteFlowField(b *testing.B) {
+	m, cells := benchMap(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.terrainChanged()
+		for _, cell := range cells {
+			if _, ok := m.nextStep(0, cell.X, cell.Y, groundProfile); !ok {
+				b.Fatal("no path")
+			}
+		}
+	}
+}
+
+// BenchmarkStepFlowField steps every enemy along a flow field that is
+// already built, which is all enemies do between terrain changes
+func BenchmarkStepFlowField(b *testing.B) {
+	m, cells := benchMap(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, cell := range cells {
+			if _, ok := m.nextStep(0, cell.X, cell.Y, groundProfile); !ok {
+				b.Fatal("no path")
+			}
+		}
+	}
+}
+
+func TestFlowFieldMatchesBFS(t *testing.T) {
+	bench, _ := benchMap(t)
+	maps := map[string]*GameMap{
+		"classic": NewGameMap(),
+		"winding": bench,
+		"maze": gridMap(t,
+			"..#.....#...",
+			"..#..#..#.#.",
+			".....#....#.",
+			"####.####.#.",
+			"..........#.",
+		),
+	}
+	for name, m := range maps {
+		t.Run(name, func(t *testing.T) {
+			for y := range m.Height {
+				for x := range m.Width {
+					from := Point{x, y}
+					if !m.Passable(x, y, false) || m.IsRouteExit(0, x, y) {
+						continue
+					}
+					want, ok := bfsPath(m, 0, from)
+					if !ok {
+						t.Fatalf("no way out of %v", from)
+					}
+
+					// Follow the flow field to the exit, one cell at a time
+					var path []Point
+					for cell := from; !m.IsRouteExit(0, cell.X, cell.Y) && len(path) <= len(want); {
+						next, ok := m.nextStep(0, cell.X, cell.Y, groundProfile)
+						if !ok || abs(next.X-cell.X)+abs(next.Y-cell.Y) != 1 {
+							t.Fatalf("flow field steps from %v to %v", cell, next)
+						}
+						path = append(path, next)
+						cell = next
+					}
+					if len(path) != len(want) {
+						t.Errorf("from %v the flow field takes %d steps, the search %d", from, len(path), len(want))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRoutePathsGroupProfiles(t *testing.T) {
+	m := NewGameMap()
+	types := currentDefs().EnemyTypes()
+
+	// Nothing on the classic map sets the ground enemies apart
+	groups := m.RoutePaths(types)
+	if len(groups) != 1 || slices.Contains(groups[0].Types, HawkEnemy) {
+		t.Fatalf("open map gave %d groups: %v", len(groups), groups)
+	}
+
+	// A freeze tower on the way turns snakes aside, but not spiders
+	trial := m.RoutePathsWithTower(FreezeTower, 8, 5, types)
+	snakes, spiders := pathsOf(trial, SnakeEnemy), pathsOf(trial, SpiderEnemy)
+	if snakes == nil || spiders == nil || slices.EqualFunc(snakes, spiders, slices.Equal) {
+		t.Fatalf("snakes and spiders share a path past a freeze tower: %v", trial)
+	}
+	if len(m.Towers) != 0 || m.Terrain[5][8] != Empty {
+		t.Error("trying a tower changed the map")
+	}
+
+	// The answer is kept for the same cell until the terrain changes
+	if again := m.RoutePathsWithTower(FreezeTower, 8, 5, types); &again[0] != &trial[0] {
+		t.Error("asking about the same cell searched the map again")
+	}
+	m.buildTower(DartTower, 2, 2)
+	if again := m.RoutePathsWithTower(FreezeTower, 8, 5, types); &again[0] == &trial[0] {
+		t.Error("trial paths outlived a change to the terrain")
+	}
+}
+
+// pathsOf returns the paths enemies of a type walk in a list of groups
+func pathsOf(groups []PathGroup, enemyType EnemyType) [][]Point {
+	for _, group := range groups {
+		if slices.Contains(group.Types, enemyType) {
+			return group.Paths
+		}
+	}
+	return nil
+}
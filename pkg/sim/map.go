@@ -0,0 +1,413 @@
+package sim
+
+import (
+	"math"
+)
+
+// GameMap represents the game map
+type GameMap struct {
+	Name          string
+	Width, Height int
+	CellSize      int
+	Terrain       [][]TerrainType
+	Towers        []*Tower
+	Entrances     []Span // Border cells enemies spawn on
+	Exits         []Span // Border cells enemies leave through
+	Routes        []Route
+
+	defs  *Defs               // Definitions the towers are built and paths weighed with
+	flow  map[flowKey][][]int // Flow fields by exit; see flowField
+	trial *trialPaths         // Last answer of RoutePathsWithTower
+}
+
+// NewGameMap creates the default built-in map
+func NewGameMap() *GameMap {
+	layout, err := BuiltinMap(DefaultMap)
+	if err != nil {
+		panic(err)
+	}
+	return layout.Build(currentDefs())
+}
+
+// CellCenter returns the world position of the center of a grid cell
+func (m *GameMap) CellCenter(x, y int) (float64, float64) {
+	return float64(x*m.CellSize + m.CellSize/2), float64(y*m.CellSize + m.CellSize/2)
+}
+
+// CellAt returns the grid cell containing a world position, clamped to the map
+func (m *GameMap) CellAt(x, y float64) (int, int) {
+	cellX := int(x / float64(m.CellSize))
+	cellY := int(y / float64(m.CellSize))
+	return min(max(cellX, 0), m.Width-1), min(max(cellY, 0), m.Height-1)
+}
+
+// IsBlocked checks if a position is blocked for ground units by a tower,
+// rock, water or the edge of the map
+func (m *GameMap) IsBlocked(x, y int) bool {
+	return !m.Passable(x, y, false)
+}
+
+// Passable reports whether a unit can cross a cell. Towers and rock stop
+// everything; water only stops units that can't fly.
+func (m *GameMap) Passable(x, y int, flying bool) bool {
+	// Check bounds
+	if x < 0 || x >= m.Width || y < 0 || y >= m.Height {
+		return false
+	}
+
+	switch m.Terrain[y][x] {
+	case TowerPlacement, Rock:
+		return false
+	case Water:
+		return flying
+	}
+	return true
+}
+
+// SpeedFactor returns the multiplier a cell applies to a unit's speed
+func (m *GameMap) SpeedFactor(x, y int, flying bool) float64 {
+	if !flying && m.Terrain[y][x] == Mud {
+		return MudSpeed
+	}
+	return 1
+}
+
+// CanPlaceTower checks if a position is suitable for tower placement
+func (m *GameMap) CanPlaceTower(x, y int) bool {
+	return m.checkCell(x, y) == nil
+}
+
+// checkCell reports why a tower can't be built on a cell, ignoring paths
+func (m *GameMap) checkCell(x, y int) error {
+	// Check if position is within bounds
+	if x < 0 || x >= m.Width || y < 0 || y >= m.Height {
+		return ErrOutOfBounds
+	}
+
+	// Can't place in entrance or exit areas
+	if m.IsEntrance(x, y) || m.IsExit(x, y) {
+		return ErrCellReserved
+	}
+
+	switch m.Terrain[y][x] {
+	case TowerPlacement:
+		return ErrCellOccupied
+	case Rock, Water, NoBuild:
+		return ErrUnbuildable
+	}
+	return nil
+}
+
+// PlaceTower attempts to place a tower of the given type at the specified
+// position and returns the new tower, or the reason it can't be built
+func (m *GameMap) PlaceTower(towerType TowerType, x, y int) (*Tower, error) {
+	if err := m.checkPlacement(x, y, nil); err != nil {
+		return nil, err
+	}
+	return m.buildTower(towerType, x, y), nil
+}
+
+// checkPlacement checks that a tower can go on a cell without cutting off
+// a route or any of the enemies on the field
+func (m *GameMap) checkPlacement(x, y int, enemies []*Enemy) error {
+	if err := m.checkCell(x, y); err != nil {
+		return err
+	}
+	if !m.checkPathExists(x, y) {
+		return ErrBlocksPath
+	}
+	return m.checkEnemies(x, y, enemies)
+}
+
+// checkEnemies makes sure a tower on a cell would leave every ground enemy
+// a path to its exit. Flyers don't care, but nothing can be built on top
+// of an enemy.
+func (m *GameMap) checkEnemies(x, y int, enemies []*Enemy) error {
+	for _, enemy := range enemies {
+		if enemy == nil || enemy.CanFly {
+			continue
+		}
+		if cellX, cellY := m.CellAt(enemy.X, enemy.Y); cellX == x && cellY == y {
+			return ErrEnemyInTheWay
+		}
+	}
+
+	// Try the tower out on the cell
+	originalTerrain := m.Terrain[y][x]
+	m.Terrain[y][x] = TowerPlacement
+	defer func() { m.Terrain[y][x] = originalTerrain }()
+
+	// Enemies heading for the same exit share one search
+	reachable := make(map[int][][]bool)
+	for _, enemy := range enemies {
+		if enemy == nil || enemy.CanFly {
+			continue
+		}
+		exit := m.Routes[enemy.Route].Exit
+		visited, ok := reachable[exit]
+		if !ok {
+			visited = m.reachableFrom(m.Exits[exit-1])
+			reachable[exit] = visited
+		}
+
+		// An enemy caught on a blocked cell only needs a way out next to it
+		cellX, cellY := m.CellAt(enemy.X, enemy.Y)
+		free := visited[cellY][cellX]
+		for _, step := range steps {
+			nextX, nextY := cellX+step.X, cellY+step.Y
+			free = free || !m.IsBlocked(nextX, nextY) && visited[nextY][nextX]
+		}
+		if !free {
+			return ErrTrapsEnemy
+		}
+	}
+	return nil
+}
+
+// buildTower puts up a tower on a cell that has passed the placement checks
+func (m *GameMap) buildTower(towerType TowerType, x, y int) *Tower {
+	tower := NewTower(m.defs.tower(towerType), x, y, m.CellSize)
+	m.Terrain[y][x] = TowerPlacement
+	m.terrainChanged()
+	m.Towers = append(m.Towers, tower)
+	return tower
+}
+
+// GetTowerAt returns the tower at the specified position or nil if there isn't one
+func (m *GameMap) GetTowerAt(x, y int) *Tower {
+	for _, tower := range m.Towers {
+		if tower.Position.X == x && tower.Position.Y == y {
+			return tower
+		}
+	}
+	return nil
+}
+
+// CountTowers returns how many towers of a type stand on the map
+func (m *GameMap) CountTowers(towerType TowerType) int {
+	count := 0
+	for _, tower := range m.Towers {
+		if tower.Type == towerType {
+			count++
+		}
+	}
+	return count
+}
+
+// RemoveTower removes a tower from the specified position
+func (m *GameMap) RemoveTower(x, y int) {
+	if x >= 0 && x < m.Width && y >= 0 && y < m.Height {
+		m.Terrain[y][x] = Empty
+		m.terrainChanged()
+		// Remove tower from towers slice
+		for i, tower := range m.Towers {
+			pos := tower.GetPosition()
+			if pos.X == x && pos.Y == y {
+				m.Towers = append(m.Towers[:i], m.Towers[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// SpanCells returns the border cells covered by an entrance or exit span
+func (m *GameMap) SpanCells(span Span) []Point {
+	cells := make([]Point, 0, span.End-span.Start+1)
+	for i := span.Start; i <= span.End; i++ {
+		switch span.Edge {
+		case EdgeLeft:
+			cells = append(cells, Point{0, i})
+		case EdgeRight:
+			cells = append(cells, Point{m.Width - 1, i})
+		case EdgeTop:
+			cells = append(cells, Point{i, 0})
+		case EdgeBottom:
+			cells = append(cells, Point{i, m.Height - 1})
+		}
+	}
+	return cells
+}
+
+// inSpans reports whether a cell lies in any of the spans
+func (m *GameMap) inSpans(spans []Span, x, y int) bool {
+	for _, span := range spans {
+		for _, cell := range m.SpanCells(span) {
+			if cell.X == x && cell.Y == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsEntrance reports whether enemies spawn on a cell
+func (m *GameMap) IsEntrance(x, y int) bool {
+	return m.inSpans(m.Entrances, x, y)
+}
+
+// IsExit reports whether enemies leave through a cell
+func (m *GameMap) IsExit(x, y int) bool {
+	return m.inSpans(m.Exits, x, y)
+}
+
+// RouteEntrance returns the entrance enemies on a route spawn at
+func (m *GameMap) RouteEntrance(route int) Span {
+	return m.Entrances[m.Routes[route].Entrance-1]
+}
+
+// RouteExit returns the exit enemies on a route are heading for
+func (m *GameMap) RouteExit(route int) Span {
+	return m.Exits[m.Routes[route].Exit-1]
+}
+
+// IsRouteExit reports whether enemies on a route leave through a cell
+func (m *GameMap) IsRouteExit(route, x, y int) bool {
+	return m.inSpans([]Span{m.RouteExit(route)}, x, y)
+}
+
+// NearestExit returns the exit cell closest to a world position, the one a
+// flyer heads for
+func (m *GameMap) NearestExit(x, y float64) Point {
+	var nearest Point
+	best := math.Inf(1)
+	for _, span := range m.Exits {
+		for _, cell := range m.SpanCells(span) {
+			cellX, cellY := m.CellCenter(cell.X, cell.Y)
+			if dist := math.Hypot(cellX-x, cellY-y); dist < best {
+				best, nearest = dist, cell
+			}
+		}
+	}
+	return nearest
+}
+
+// EntranceDepth returns how many cells a cell lies in from the nearest
+// edge that has an entrance on it
+func (m *GameMap) EntranceDepth(x, y int) int {
+	depth := m.Width + m.Height
+	for _, span := range m.Entrances {
+		switch span.Edge {
+		case EdgeLeft:
+			depth = min(depth, x)
+		case EdgeRight:
+			depth = min(depth, m.Width-1-x)
+		case EdgeTop:
+			depth = min(depth, y)
+		case EdgeBottom:
+			depth = min(depth, m.Height-1-y)
+		}
+	}
+	return depth
+}
+
+// SpawnPoint returns the world position on the outer edge of an entrance
+// cell where an enemy appears
+func (m *GameMap) SpawnPoint(span Span, cell Point) (float64, float64) {
+	x, y := m.CellCenter(cell.X, cell.Y)
+	switch span.Edge {
+	case EdgeLeft:
+		x = 0
+	case EdgeRight:
+		x = float64(m.Width * m.CellSize)
+	case EdgeTop:
+		y = 0
+	case EdgeBottom:
+		y = float64(m.Height * m.CellSize)
+	}
+	return x, y
+}
+
+// GetTowersInRange returns all towers within range of a point
+func (m *GameMap) GetTowersInRange(x, y, range_ float64) []*Tower {
+	var nearbyTowers []*Tower
+	for _, tower := range m.Towers {
+		if tower == nil {
+			continue
+		}
+
+		towerX, towerY := m.CellCenter(tower.Position.X, tower.Position.Y)
+
+		dx := towerX - x
+		dy := towerY - y
+		dist := math.Sqrt(dx*dx + dy*dy)
+
+		if dist <= range_ {
+			nearbyTowers = append(nearbyTowers, tower)
+		}
+	}
+	return nearbyTowers
+}
+
+// checkPathExists uses breadth-first search to verify that every route
+// still has a path for ground units from its entrance to its exit with a
+// tower on the test cell. Pass -1, -1 to check the map as it is.
+func (m *GameMap) checkPathExists(testX, testY int) bool {
+	// Temporarily place tower for testing
+	if testX >= 0 && testY >= 0 {
+		originalTerrain := m.Terrain[testY][testX]
+		m.Terrain[testY][testX] = TowerPlacement
+		defer func() { m.Terrain[testY][testX] = originalTerrain }()
+	}
+
+	// Routes sharing an exit share one search
+	reachable := make(map[int][][]bool)
+	for i, route := range m.Routes {
+		visited, ok := reachable[route.Exit]
+		if !ok {
+			visited = m.reachableFrom(m.RouteExit(i))
+			reachable[route.Exit] = visited
+		}
+
+		// The entrance needs at least one cell that reaches the exit
+		open := false
+		for _, cell := range m.SpanCells(m.RouteEntrance(i)) {
+			if visited[cell.Y][cell.X] {
+				open = true
+				break
+			}
+		}
+		if !open {
+			return false
+		}
+	}
+	return true
+}
+
+// reachableFrom marks every cell with an open path to the given exit
+func (m *GameMap) reachableFrom(exit Span) [][]bool {
+	// Create visited array
+	visited := make([][]bool, m.Height)
+	for i := range visited {
+		visited[i] = make([]bool, m.Width)
+	}
+
+	// Search backwards from every cell of the exit at once
+	queue := []Point{}
+	for _, cell := range m.SpanCells(exit) {
+		if !m.IsBlocked(cell.X, cell.Y) && !visited[cell.Y][cell.X] {
+			queue = append(queue, cell)
+			visited[cell.Y][cell.X] = true
+		}
+	}
+
+	// BFS
+	for len(queue) > 0 {
+		// Pop front of queue
+		current := queue[0]
+		queue = queue[1:]
+
+		// Try all four directions
+		directions := [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+		for _, dir := range directions {
+			newX := current.X + dir[0]
+			newY := current.Y + dir[1]
+
+			// Check bounds and if not visited and not blocked
+			if !m.IsBlocked(newX, newY) && !visited[newY][newX] {
+				queue = append(queue, Point{newX, newY})
+				visited[newY][newX] = true
+			}
+		}
+	}
+	return visited
+}
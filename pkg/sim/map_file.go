@@ -0,0 +1,333 @@
+package sim
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+//go:embed data/maps/*.json
+var builtinMaps embed.FS
+
+// DefaultMap is the built-in map new matches use unless told otherwise
+const DefaultMap = "classic"
+
+// Starting resources for maps that don't set their own
+const (
+	DefaultMoney = 200 // Enough for any basic tower setup
+	DefaultLives = 20
+)
+
+// Edge is a side of the map that enemies enter or leave through
+type Edge int
+
+const (
+	EdgeLeft Edge = iota
+	EdgeRight
+	EdgeTop
+	EdgeBottom
+)
+
+// edgeNames are the names edges are stored under in map files
+var edgeNames = map[Edge]string{
+	EdgeLeft:   "left",
+	EdgeRight:  "right",
+	EdgeTop:    "top",
+	EdgeBottom: "bottom",
+}
+
+// MarshalText stores an edge by name
+func (e Edge) MarshalText() ([]byte, error) {
+	name, ok := edgeNames[e]
+	if !ok {
+		return nil, fmt.Errorf("unknown edge %d", e)
+	}
+	return []byte(name), nil
+}
+
+// UnmarshalText reads an edge stored by name
+func (e *Edge) UnmarshalText(text []byte) error {
+	for edge, name := range edgeNames {
+		if name == string(text) {
+			*e = edge
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown edge %q", text)
+}
+
+// Span is a run of border cells along one edge of the map
+type Span struct {
+	Edge  Edge `json:"edge"`
+	Start int  `json:"start"` // First row on the left and right edges, first column on the top and bottom
+	End   int  `json:"end"`   // Last row or column, inclusive
+}
+
+// Route sends enemies from one entrance to one exit. Both count from 1, in
+// the order the map lists them.
+type Route struct {
+	Entrance int `json:"entrance"`
+	Exit     int `json:"exit"`
+}
+
+// MapTower is a tower that already stands on the map when a match starts.
+// It was free, so selling it refunds nothing.
+type MapTower struct {
+	Tower string `json:"tower"` // Tower id
+	X     int    `json:"x"`
+	Y     int    `json:"y"`
+}
+
+// gridTerrain maps the characters of a map file grid to terrain
+var gridTerrain = map[rune]TerrainType{
+	'.': Empty,
+	'#': Rock,
+	'x': NoBuild,
+	'~': Water,
+	'm': Mud,
+}
+
+// terrainGrid is the reverse of gridTerrain, for writing grids
+var terrainGrid = map[TerrainType]rune{
+	Empty:   '.',
+	Rock:    '#',
+	NoBuild: 'x',
+	Water:   '~',
+	Mud:     'm',
+}
+
+// MapFile is the on-disk description of a map. Grid holds one string per
+// row, with '.' for open ground, '#' for rock, '~' for water, 'm' for mud
+// and 'x' for cells enemies can walk through but towers can't be built on.
+// An empty grid means the whole map is open. Without routes, each entrance leads to the
+// exit listed in the same position when there are as many of each, and to
+// every exit otherwise.
+type MapFile struct {
+	Name      string     `json:"name"`
+	Width     int        `json:"width"`
+	Height    int        `json:"height"`
+	CellSize  int        `json:"cellSize"` // Cell size in pixels
+	Entrances []Span     `json:"entrances"`
+	Exits     []Span     `json:"exits"`
+	Routes    []Route    `json:"routes,omitempty"`
+	Grid      []string   `json:"grid,omitempty"`
+	Towers    []MapTower `json:"towers,omitempty"`
+	Money     int        `json:"money,omitempty"` // Starting money; 0 uses DefaultMoney
+	Lives     int        `json:"lives,omitempty"` // Starting lives; 0 uses DefaultLives
+}
+
+// ParseMap decodes and checks a map file
+func ParseMap(data []byte) (*MapFile, error) {
+	var file MapFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if err := file.validate(); err != nil {
+		return nil, fmt.Errorf("map %q: %w", file.Name, err)
+	}
+	return &file, nil
+}
+
+// LoadMap reads a map file from disk
+func LoadMap(path string) (*MapFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := ParseMap(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return file, nil
+}
+
+// Save writes the map file to disk
+func (f *MapFile) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// BuiltinMaps returns the names of the maps shipped with the game
+func BuiltinMaps() []string {
+	entries, _ := builtinMaps.ReadDir("data/maps")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BuiltinMap returns one of the maps shipped with the game
+func BuiltinMap(name string) (*MapFile, error) {
+	data, err := builtinMaps.ReadFile(path.Join("data/maps", name+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("no built-in map %q", name)
+	}
+	return ParseMap(data)
+}
+
+// Build creates a fresh game map from the map file, with only its
+// pre-built towers standing, for a match played with the given definitions
+func (f *MapFile) Build(defs *Defs) *GameMap {
+	m, _ := f.build(defs)
+	return m
+}
+
+// build creates a fresh game map, leaving out any pre-built tower that
+// can't stand where the file puts it and reporting the first one left out
+func (f *MapFile) build(defs *Defs) (*GameMap, error) {
+	m := &GameMap{
+		Name:      f.Name,
+		Width:     f.Width,
+		Height:    f.Height,
+		CellSize:  f.CellSize,
+		Towers:    make([]*Tower, 0),
+		Entrances: append([]Span(nil), f.Entrances...),
+		Exits:     append([]Span(nil), f.Exits...),
+		Routes:    f.routes(),
+		defs:      defs,
+	}
+
+	// Initialize terrain - cells are empty unless the grid says otherwise
+	m.Terrain = make([][]TerrainType, m.Height)
+	for y := range m.Terrain {
+		m.Terrain[y] = make([]TerrainType, m.Width)
+		if y >= len(f.Grid) {
+			continue
+		}
+		for x, c := range f.Grid[y] {
+			m.Terrain[y][x] = gridTerrain[c]
+		}
+	}
+
+	// Put up the pre-built towers
+	var problem error
+	for i, placed := range f.Towers {
+		towerType, ok := towerIDs[placed.Tower]
+		def := defs.tower(towerType)
+		err := m.checkCell(placed.X, placed.Y)
+		switch {
+		case !ok || def == nil:
+			err = fmt.Errorf("%w %q", ErrUnknownTower, placed.Tower)
+		case err == nil:
+			tower := NewTower(def, placed.X, placed.Y, m.CellSize)
+			tower.Cost = 0
+			m.Terrain[placed.Y][placed.X] = TowerPlacement
+			m.Towers = append(m.Towers, tower)
+			continue
+		}
+		if problem == nil {
+			problem = fmt.Errorf("tower %d: %w at %d,%d", i+1, err, placed.X, placed.Y)
+		}
+	}
+	return m, problem
+}
+
+// StartingMoney returns the money a match on the map starts with
+func (f *MapFile) StartingMoney() int {
+	if f.Money > 0 {
+		return f.Money
+	}
+	return DefaultMoney
+}
+
+// StartingLives returns the lives a match on the map starts with
+func (f *MapFile) StartingLives() int {
+	if f.Lives > 0 {
+		return f.Lives
+	}
+	return DefaultLives
+}
+
+// fits reports whether a span lies along its edge of the map
+func (f *MapFile) fits(span Span) bool {
+	length := f.Height
+	if span.Edge == EdgeTop || span.Edge == EdgeBottom {
+		length = f.Width
+	}
+	return span.Start >= 0 && span.End >= span.Start && span.End < length
+}
+
+// routes returns the routes enemies take across the map
+func (f *MapFile) routes() []Route {
+	if len(f.Routes) > 0 {
+		return append([]Route(nil), f.Routes...)
+	}
+	var routes []Route
+	for entrance := range f.Entrances {
+		for exit := range f.Exits {
+			if len(f.Entrances) != len(f.Exits) || entrance == exit {
+				routes = append(routes, Route{Entrance: entrance + 1, Exit: exit + 1})
+			}
+		}
+	}
+	return routes
+}
+
+// validate checks a map file for layouts the simulation can't play
+func (f *MapFile) validate() error {
+	if f.Width < 2 || f.Height < 2 || f.CellSize <= 0 {
+		return fmt.Errorf("bad size %dx%d with %dpx cells", f.Width, f.Height, f.CellSize)
+	}
+	if len(f.Grid) != 0 && len(f.Grid) != f.Height {
+		return fmt.Errorf("grid has %d rows, want %d", len(f.Grid), f.Height)
+	}
+	for y, row := range f.Grid {
+		if len(row) != f.Width {
+			return fmt.Errorf("grid row %d has %d cells, want %d", y, len(row), f.Width)
+		}
+		for _, c := range row {
+			if _, ok := gridTerrain[c]; !ok {
+				return fmt.Errorf("grid row %d: unknown cell %q", y, c)
+			}
+		}
+	}
+	if len(f.Entrances) == 0 || len(f.Exits) == 0 {
+		return fmt.Errorf("needs at least one entrance and one exit")
+	}
+
+	for i, route := range f.Routes {
+		if route.Entrance < 1 || route.Entrance > len(f.Entrances) || route.Exit < 1 || route.Exit > len(f.Exits) {
+			return fmt.Errorf("route %d: no entrance %d or exit %d", i+1, route.Entrance, route.Exit)
+		}
+	}
+
+	if f.Money < 0 || f.Lives < 0 {
+		return fmt.Errorf("negative starting money or lives")
+	}
+
+	for _, span := range append(append([]Span(nil), f.Entrances...), f.Exits...) {
+		if !f.fits(span) {
+			return fmt.Errorf("span %d-%d doesn't fit the %s edge", span.Start, span.End, edgeNames[span.Edge])
+		}
+	}
+
+	m, err := f.build(currentDefs())
+	if err != nil {
+		return err
+	}
+	used := make(map[Point]bool)
+	for _, span := range append(append([]Span(nil), f.Entrances...), f.Exits...) {
+		for _, cell := range m.SpanCells(span) {
+			if m.IsBlocked(cell.X, cell.Y) {
+				return fmt.Errorf("span %d-%d on the %s edge is blocked", span.Start, span.End, edgeNames[span.Edge])
+			}
+			if used[cell] {
+				return fmt.Errorf("span %d-%d on the %s edge overlaps another", span.Start, span.End, edgeNames[span.Edge])
+			}
+			used[cell] = true
+		}
+	}
+	if !m.checkPathExists(-1, -1) {
+		return fmt.Errorf("a route has no path from its entrance to its exit")
+	}
+	return nil
+}
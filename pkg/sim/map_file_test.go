@@ -0,0 +1,145 @@
+package sim
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// gridLayout describes a map from a grid, with its entrance and exit at
+// the bottom of the left and right edges
+func gridLayout(t *testing.T, grid ...string) *MapFile {
+	t.Helper()
+	layout := &MapFile{
+		Name:      "Test",
+		Width:     len(grid[0]),
+		Height:    len(grid),
+		CellSize:  10,
+		Entrances: []Span{{Edge: EdgeLeft, Start: len(grid) - 1, End: len(grid) - 1}},
+		Exits:     []Span{{Edge: EdgeRight, Start: len(grid) - 1, End: len(grid) - 1}},
+		Grid:      grid,
+	}
+	if err := layout.validate(); err != nil {
+		t.Fatal(err)
+	}
+	return layout
+}
+
+// gridMap builds the map gridLayout describes
+func gridMap(t *testing.T, grid ...string) *GameMap {
+	t.Helper()
+	return gridLayout(t, grid...).Build(currentDefs())
+}
+
+func TestBuiltinMapsRoundTrip(t *testing.T) {
+	for _, name := range BuiltinMaps() {
+		t.Run(name, func(t *testing.T) {
+			layout, err := BuiltinMap(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := json.Marshal(layout)
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, err := ParseMap(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(again, layout) {
+				t.Errorf("map changed on the way through a file:\n%+v\n%+v", layout, again)
+			}
+		})
+	}
+}
+
+func TestParseMapRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want string // Part of the error
+	}{
+		{"not json", `{"name": "Bad"`, "unexpected end"},
+		{"too small", `{"width": 1, "height": 4, "cellSize": 10}`, "bad size 1x4"},
+		{"no cell size", `{"width": 4, "height": 4}`, "bad size"},
+		{"short grid", `{"width": 3, "height": 2, "cellSize": 10, "grid": ["..."]}`, "grid has 1 rows, want 2"},
+		{"ragged grid", `{"width": 3, "height": 2, "cellSize": 10, "grid": ["...", "...."]}`, "grid row 1 has 4 cells"},
+		{"unknown cell", `{"width": 3, "height": 2, "cellSize": 10, "grid": ["...", ".?."]}`, "unknown cell '?'"},
+		{"no exit", `{"width": 3, "height": 2, "cellSize": 10,
+			"entrances": [{"edge": "left", "start": 0, "end": 1}]}`, "at least one entrance and one exit"},
+		{"unknown edge", `{"width": 3, "height": 2, "cellSize": 10,
+			"entrances": [{"edge": "middle", "start": 0, "end": 1}]}`, `unknown edge "middle"`},
+		{"span off the edge", `{"width": 3, "height": 2, "cellSize": 10,
+			"entrances": [{"edge": "left", "start": 0, "end": 2}],
+			"exits": [{"edge": "right", "start": 0, "end": 1}]}`, "span 0-2 doesn't fit the left edge"},
+		{"span on rock", `{"width": 3, "height": 2, "cellSize": 10, "grid": ["#..", "..."],
+			"entrances": [{"edge": "left", "start": 0, "end": 0}],
+			"exits": [{"edge": "right", "start": 0, "end": 1}]}`, "span 0-0 on the left edge is blocked"},
+		{"walled off", `{"width": 3, "height": 2, "cellSize": 10, "grid": [".#.", ".#."],
+			"entrances": [{"edge": "left", "start": 0, "end": 1}],
+			"exits": [{"edge": "right", "start": 0, "end": 1}]}`, "no path from its entrance to its exit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseMap([]byte(tt.file))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want an error with %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMapFileBuild(t *testing.T) {
+	m := gridMap(t,
+		"..x~",
+		".#m.",
+		"....",
+	)
+	if m.Width != 4 || m.Height != 3 || m.CellSize != 10 || len(m.Towers) != 0 {
+		t.Fatalf("built a %dx%d map with %dpx cells and %d towers", m.Width, m.Height, m.CellSize, len(m.Towers))
+	}
+	for y, row := range []string{"..x~", ".#m.", "...."} {
+		for x, c := range row {
+			want := map[rune]TerrainType{'.': Empty, '#': Rock, 'x': NoBuild, '~': Water, 'm': Mud}[c]
+			if m.Terrain[y][x] != want {
+				t.Errorf("cell %d,%d is %v, want %v", x, y, m.Terrain[y][x], want)
+			}
+		}
+	}
+}
+
+func TestMapRoutes(t *testing.T) {
+	left := func(row int) Span { return Span{Edge: EdgeLeft, Start: row, End: row} }
+	right := func(row int) Span { return Span{Edge: EdgeRight, Start: row, End: row} }
+
+	tests := []struct {
+		name      string
+		entrances []Span
+		exits     []Span
+		routes    []Route
+		want      []Route
+	}{
+		{"one of each", []Span{left(0)}, []Span{right(0)}, nil, []Route{{1, 1}}},
+		{"paired in order", []Span{left(0), left(3)}, []Span{right(0), right(3)}, nil, []Route{{1, 1}, {2, 2}}},
+		{"one entrance to every exit", []Span{left(0)}, []Span{right(0), right(3)}, nil, []Route{{1, 1}, {1, 2}}},
+		{"every entrance to one exit", []Span{left(0), left(3)}, []Span{right(0)}, nil, []Route{{1, 1}, {2, 1}}},
+		{"listed", []Span{left(0), left(3)}, []Span{right(0), right(3)}, []Route{{1, 2}, {2, 2}}, []Route{{1, 2}, {2, 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := &MapFile{Width: 4, Height: 4, CellSize: 10, Entrances: tt.entrances, Exits: tt.exits, Routes: tt.routes}
+			if err := layout.validate(); err != nil {
+				t.Fatal(err)
+			}
+			if got := layout.Build(currentDefs()).Routes; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("routes %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	bad := &MapFile{Width: 4, Height: 4, CellSize: 10, Entrances: []Span{left(0)}, Exits: []Span{right(0)}, Routes: []Route{{1, 2}}}
+	if err := bad.validate(); err == nil || !strings.Contains(err.Error(), "route 1: no entrance 1 or exit 2") {
+		t.Errorf("route to a missing exit gave %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package sim
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// walk sends an enemy of a type from the entrance of a map to its exit and
+// returns the cells it crossed, in order
+func walk(t *testing.T, layout *MapFile, enemyType EnemyType) []Point {
+	t.Helper()
+	w := NewWorldOnMap(1, layout)
+	span := w.Map.RouteEntrance(0)
+	x, y := w.Map.SpawnPoint(span, w.Map.SpanCells(span)[0])
+	e := NewEnemy(x, y, w.Map.CellSize, w.EnemyDefFor(enemyType), 1, w.EnemyDefFor(enemyType))
+
+	var cells []Point
+	for range 10000 {
+		cellX, cellY := w.Map.CellAt(e.X, e.Y)
+		if cell := (Point{cellX, cellY}); len(cells) == 0 || cells[len(cells)-1] != cell {
+			cells = append(cells, cell)
+		}
+		if e.Update(w) {
+			return cells
+		}
+	}
+	t.Fatalf("%v never got out", cells)
+	return nil
+}
+
+func TestMudPathCost(t *testing.T) {
+	tests := []struct {
+		name  string
+		grid  []string
+		enemy EnemyType
+		mud   int // Mud cells crossed
+	}{
+		{"around a short detour", []string{
+			".......",
+			".......",
+			"..mmm..",
+		}, SpiderEnemy, 0},
+		{"through a thin strip rather than far round", []string{
+			".......",
+			"...#...",
+			"...#...",
+			"...#...",
+			"...m...",
+		}, SpiderEnemy, 1},
+		{"through the only way", []string{
+			"...#...",
+			"...#...",
+			"...m...",
+		}, SpiderEnemy, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := gridLayout(t, tt.grid...)
+			m := layout.Build(currentDefs())
+			mud := 0
+			for _, cell := range walk(t, layout, tt.enemy) {
+				if m.Terrain[cell.Y][cell.X] == Mud {
+					mud++
+				}
+			}
+			if mud != tt.mud {
+				t.Errorf("crossed %d mud cells, want %d", mud, tt.mud)
+			}
+		})
+	}
+}
+
+func TestTerrainRules(t *testing.T) {
+	m := gridMap(t,
+		".#~m",
+		"....",
+	)
+	tests := []struct {
+		cell             Point
+		walk, fly, build bool
+		speed            float64
+	}{
+		{Point{0, 0}, true, true, true, 1},
+		{Point{1, 0}, false, false, false, 1},
+		{Point{2, 0}, false, true, false, 1},
+		{Point{3, 0}, true, true, true, MudSpeed},
+	}
+	for _, tt := range tests {
+		x, y := tt.cell.X, tt.cell.Y
+		if m.Passable(x, y, false) != tt.walk || m.Passable(x, y, true) != tt.fly || m.CanPlaceTower(x, y) != tt.build {
+			t.Errorf("cell %v: walk %v, fly %v, build %v", tt.cell, m.Passable(x, y, false), m.Passable(x, y, true), m.CanPlaceTower(x, y))
+		}
+		if tt.walk && m.SpeedFactor(x, y, false) != tt.speed {
+			t.Errorf("cell %v has %g speed on foot", tt.cell, m.SpeedFactor(x, y, false))
+		}
+		if tt.fly && m.SpeedFactor(x, y, true) != 1 {
+			t.Errorf("cell %v slows flyers", tt.cell)
+		}
+	}
+	if slices.Contains([]bool{m.Passable(-1, 0, true), m.Passable(0, 2, false)}, true) {
+		t.Error("cells off the map are passable")
+	}
+}
+
+func TestCheckEnemies(t *testing.T) {
+	// The cell at 2,1 is a pocket only open to the top
+	pocket := []string{
+		".......",
+		".#.#...",
+		"..#....",
+		".......",
+		".......",
+	}
+	// The same pocket walled up, for an enemy caught on a blocked cell
+	walled := []string{
+		".......",
+		".###...",
+		"..#....",
+		".......",
+		".......",
+	}
+
+	tests := []struct {
+		name   string
+		grid   []string
+		enemy  EnemyType
+		at     Point   // Cell the enemy is on
+		offset float64 // How far right of the cell's center it is, in cells
+		build  Point
+		want   error
+	}{
+		{"on the cell", pocket, SpiderEnemy, Point{4, 3}, 0, Point{4, 3}, ErrEnemyInTheWay},
+		{"beside the cell", pocket, SpiderEnemy, Point{4, 3}, 0, Point{5, 3}, nil},
+		{"seals the pocket", pocket, SpiderEnemy, Point{2, 1}, 0, Point{2, 0}, ErrTrapsEnemy},
+		{"leaves the pocket open", pocket, SpiderEnemy, Point{2, 1}, 0, Point{4, 0}, nil},
+		{"flyer over the cell", pocket, HawkEnemy, Point{4, 3}, 0, Point{4, 3}, nil},
+		{"flyer in the pocket", pocket, HawkEnemy, Point{2, 1}, 0, Point{2, 0}, nil},
+		{"mid-step, short of the next cell", pocket, SpiderEnemy, Point{4, 3}, 0.45, Point{5, 3}, nil},
+		{"mid-step, over the line", pocket, SpiderEnemy, Point{4, 3}, 0.55, Point{5, 3}, ErrEnemyInTheWay},
+		{"on a blocked cell, a way out beside it", walled, SpiderEnemy, Point{2, 1}, 0, Point{4, 0}, nil},
+		{"on a blocked cell, its last way out", walled, SpiderEnemy, Point{2, 1}, 0, Point{2, 0}, ErrTrapsEnemy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := gridMap(t, tt.grid...)
+			x, y := m.CellCenter(tt.at.X, tt.at.Y)
+			def := m.defs.enemy(tt.enemy)
+			enemy := NewEnemy(x+tt.offset*float64(m.CellSize), y, m.CellSize, def, 1, def)
+
+			err := m.checkPlacement(tt.build.X, tt.build.Y, []*Enemy{enemy})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("building on %v: got %v, want %v", tt.build, err, tt.want)
+			}
+			if m.Terrain[tt.build.Y][tt.build.X] == TowerPlacement {
+				t.Error("checking the cell left a tower on it")
+			}
+		})
+	}
+}
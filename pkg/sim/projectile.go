@@ -0,0 +1,93 @@
+package sim
+
+import (
+	"math"
+)
+
+// ProjectileType represents different types of projectiles
+type ProjectileType int
+
+const (
+	DartProjectile ProjectileType = iota
+	BulletProjectile
+	LightningProjectile
+	FlameProjectile
+	FreezeProjectile
+)
+
+// Projectile represents a projectile shot from a tower
+type Projectile struct {
+	X, Y        float64 // Current position
+	TargetX     float64 // Target X position
+	TargetY     float64 // Target Y position
+	Speed       float64 // Movement speed
+	Damage      float64 // Damage amount
+	Type        ProjectileType
+	AntiAir     bool   // Whether it can hit flying enemies
+	Source      *Tower `json:"-"` // Tower that fired it, credited with its damage
+	ShotEffects        // Copied from the tower when fired
+}
+
+// NewProjectile creates a new projectile
+func NewProjectile(startX, startY, targetX, targetY float64, projType ProjectileType, damage float64) *Projectile {
+	// Base projectile setup
+	proj := &Projectile{
+		X:       startX,
+		Y:       startY,
+		TargetX: targetX,
+		TargetY: targetY,
+		Speed:   5.0, // Base speed for all projectiles
+		Damage:  damage,
+		Type:    projType,
+	}
+
+	// Set specific speeds based on tower type
+	switch projType {
+	case DartProjectile:
+		proj.Speed = 6.0 // Faster than base
+	case BulletProjectile:
+		proj.Speed = 8.0 // Fastest projectile
+	case LightningProjectile:
+		proj.Speed = 7.0 // Fast
+	case FlameProjectile:
+		proj.Speed = 4.0 // Slower but area effect
+	case FreezeProjectile:
+		proj.Speed = 5.0 // Medium speed
+	}
+
+	return proj
+}
+
+// Update moves the projectile and returns true if it reached its target
+func (p *Projectile) Update() bool {
+	// Calculate direction to target
+	dx := p.TargetX - p.X
+	dy := p.TargetY - p.Y
+	dist := math.Sqrt(dx*dx + dy*dy)
+
+	// If we're very close to target, we've hit
+	if dist < p.Speed {
+		return true
+	}
+
+	// Move towards target
+	p.X += (dx / dist) * p.Speed
+	p.Y += (dy / dist) * p.Speed
+
+	return false
+}
+
+// GetPosition returns the current position of the projectile
+func (p *Projectile) GetPosition() (float64, float64) {
+	return p.X, p.Y
+}
+
+// GetDamage returns the damage amount of the projectile
+func (p *Projectile) GetDamage() float64 {
+	return p.Damage
+}
+
+// GetProjectileType returns the type of the projectile
+func (p *Projectile) GetProjectileType() ProjectileType {
+	return p.Type
+}
@@ -0,0 +1,35 @@
+package sim
+
+// RNG is the simulation's random number generator (SplitMix64). Its whole
+// state is a single word, so a seed reproduces a run exactly and a saved
+// game can resume the same stream.
+type RNG struct {
+	State uint64
+}
+
+// NewRNG creates a generator from a seed
+func NewRNG(seed int64) *RNG {
+	return &RNG{State: uint64(seed)}
+}
+
+// Uint64 returns the next 64 random bits
+func (r *RNG) Uint64() uint64 {
+	r.State += 0x9e3779b97f4a7c15
+	z := r.State
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Float64 returns a random float64 in [0.0, 1.0)
+func (r *RNG) Float64() float64 {
+	return float64(r.Uint64()>>11) / (1 << 53)
+}
+
+// Intn returns a random int in [0, n). It panics if n <= 0.
+func (r *RNG) Intn(n int) int {
+	if n <= 0 {
+		panic("sim: invalid argument to Intn")
+	}
+	return int(r.Uint64() % uint64(n))
+}
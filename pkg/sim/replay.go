@@ -0,0 +1,137 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ReplayVersion is the current replay file format version. Replays can't
+// be migrated the way saves are, since a recording only plays back under
+// the rules it was made with; bump it whenever the simulation changes how
+// a match plays out, and older replays are turned away.
+const ReplayVersion = 17
+
+// ReplayEvent is a player action stamped with the frame it was applied on
+type ReplayEvent struct {
+	Frame  int64  `json:"frame"`
+	Action Action `json:"action"`
+}
+
+// Replay is a recorded match: the seed and definitions plus every player
+// action in order. Feeding the events back into a world created with the
+// same seed and definitions reproduces the match exactly.
+type Replay struct {
+	Version int   `json:"version"`
+	Seed    int64 `json:"seed"`
+	// Defs are the tower, enemy and wave definitions the match was played
+	// with, overrides included
+	Defs *Defs `json:"defs"`
+	// Map is the layout a fresh world is played on. Empty means the
+	// default map.
+	Map *MapFile `json:"map,omitempty"`
+	// Start is the saved world the recording begins from, for matches that
+	// were resumed from a save. Empty means a fresh world from Seed and Map.
+	Start  json.RawMessage `json:"start,omitempty"`
+	Events []ReplayEvent   `json:"events"`
+}
+
+// NewReplay starts an empty recording for a fresh world on a map
+func NewReplay(seed int64, layout *MapFile) *Replay {
+	return &Replay{Version: ReplayVersion, Seed: seed, Defs: currentDefs(), Map: layout}
+}
+
+// NewReplayFrom starts an empty recording that begins from a world's current state
+func NewReplayFrom(w *World) (*Replay, error) {
+	start, err := MarshalWorld(w)
+	if err != nil {
+		return nil, err
+	}
+	r := NewReplay(w.Seed, nil)
+	r.Start = start
+	return r, nil
+}
+
+// NewWorld creates the world the recording starts from, played with the
+// recording's definitions. The definitions loaded for other matches are
+// left alone.
+func (r *Replay) NewWorld() (*World, error) {
+	if r.Defs == nil {
+		return nil, fmt.Errorf("replay has no definitions")
+	}
+	if len(r.Start) > 0 {
+		return unmarshalWorld(r.Start, r.Defs)
+	}
+	layout := r.Map
+	if layout == nil {
+		var err error
+		if layout, err = BuiltinMap(DefaultMap); err != nil {
+			return nil, err
+		}
+	} else if err := layout.validate(); err != nil {
+		return nil, err
+	}
+	return newWorld(r.Seed, layout, r.Defs), nil
+}
+
+// Record appends the actions applied on a frame
+func (r *Replay) Record(frame int64, actions []Action) {
+	for _, action := range actions {
+		r.Events = append(r.Events, ReplayEvent{Frame: frame, Action: action})
+	}
+}
+
+// Save writes the replay to a file
+func (r *Replay) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LoadReplay reads a replay file
+func LoadReplay(path string) (*Replay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var r Replay
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("reading replay %s: %w", path, err)
+	}
+	if r.Version != ReplayVersion {
+		return nil, fmt.Errorf("replay %s has version %d, but this game plays version %d", path, r.Version, ReplayVersion)
+	}
+	if r.Defs == nil {
+		return nil, fmt.Errorf("replay %s has no definitions", path)
+	}
+	return &r, nil
+}
+
+// ReplayPlayer hands out a replay's actions frame by frame
+type ReplayPlayer struct {
+	replay *Replay
+	next   int // Index of the next event to hand out
+}
+
+// NewReplayPlayer starts playback from the first event
+func NewReplayPlayer(r *Replay) *ReplayPlayer {
+	return &ReplayPlayer{replay: r}
+}
+
+// Actions returns the recorded actions for a frame
+func (p *ReplayPlayer) Actions(frame int64) []Action {
+	var actions []Action
+	for p.next < len(p.replay.Events) && p.replay.Events[p.next].Frame <= frame {
+		actions = append(actions, p.replay.Events[p.next].Action)
+		p.next++
+	}
+	return actions
+}
+
+// Done reports whether every recorded action has been played
+func (p *ReplayPlayer) Done() bool {
+	return p.next >= len(p.replay.Events)
+}
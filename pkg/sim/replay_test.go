@@ -0,0 +1,185 @@
+package sim
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// matchTicks is how long the scripted match runs: well into the first
+// waves, with every kind of shot fired
+const matchTicks = 2400
+
+// scriptedActions are the player actions of a short match: one tower of
+// each kind with an effect, target changes, the first wave and upgrades
+// once kills have paid for them
+func scriptedActions(frame int64) []Action {
+	switch frame {
+	case 0:
+		return []Action{
+			{Kind: ActionSelectTower, Tower: LightningTower},
+			{Kind: ActionPlaceTower, X: 5, Y: 5},
+			{Kind: ActionSelectTower, Tower: FlameTower},
+			{Kind: ActionPlaceTower, X: 8, Y: 7},
+			{Kind: ActionSelectTower, Tower: FreezeTower},
+			{Kind: ActionPlaceTower, X: 3, Y: 7},
+			{Kind: ActionSelectTower, Tower: DartTower},
+			{Kind: ActionPlaceTower, X: 11, Y: 5},
+			{Kind: ActionSetTarget, X: 5, Y: 5, Target: TargetFirst},
+			{Kind: ActionSetTarget, X: 3, Y: 7, Target: TargetUnfrozen},
+			{Kind: ActionSetTarget, X: 8, Y: 7, Target: TargetStrongest},
+		}
+	case 1:
+		return []Action{{Kind: ActionBegin}}
+	case 900, 1500:
+		return []Action{{Kind: ActionUpgradeTower, X: 11, Y: 5}}
+	case 1200:
+		return []Action{{Kind: ActionUpgradeTower, X: 5, Y: 5}}
+	}
+	return nil
+}
+
+// runMatch ticks a world from its current frame up to the given one,
+// taking actions from a script and recording them if rec isn't nil
+func runMatch(w *World, until int64, actions func(frame int64) []Action, rec *Replay) {
+	for w.Frame < until {
+		frameActions := actions(w.Frame)
+		if rec != nil {
+			rec.Record(w.Frame, frameActions)
+		}
+		w.Tick(frameActions)
+	}
+}
+
+// worldState encodes the complete state of a world for comparing two
+func worldState(t *testing.T, w *World) string {
+	t.Helper()
+	data, err := MarshalWorld(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// useDefs loads definitions for new matches the way definition files
+// would, and puts the ones loaded before back once the test is over
+func useDefs(t *testing.T, defs *Defs) {
+	saved := currentDefs()
+	towerDefs, enemyDefs, waveScript = defs.Towers, defs.Enemies, defs.Waves
+	t.Cleanup(func() {
+		towerDefs, enemyDefs, waveScript = saved.Towers, saved.Enemies, saved.Waves
+	})
+}
+
+// recordMatch plays the scripted match on a fresh world and returns the
+// world and its recording, saved and loaded back from a file
+func recordMatch(t *testing.T, seed int64) (*World, *Replay) {
+	t.Helper()
+	w := NewWorld(seed)
+	rec := NewReplay(seed, w.Layout)
+	runMatch(w, matchTicks, scriptedActions, rec)
+
+	path := filepath.Join(t.TempDir(), "replay.json")
+	if err := rec.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadReplay(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w, loaded
+}
+
+// playReplay plays a replay from the start up to the frame it was
+// recorded to
+func playReplay(t *testing.T, r *Replay) *World {
+	t.Helper()
+	w, err := r.NewWorld()
+	if err != nil {
+		t.Fatal(err)
+	}
+	player := NewReplayPlayer(r)
+	runMatch(w, matchTicks, player.Actions, nil)
+	return w
+}
+
+func TestReplayReproducesMatch(t *testing.T) {
+	original, rec := recordMatch(t, 42)
+	kills := 0
+	for _, tower := range original.Map.Towers {
+		kills += tower.Kills
+	}
+	if kills == 0 || len(original.Map.Towers) != 4 {
+		t.Fatalf("scripted match went nowhere: %d kills, %d towers", kills, len(original.Map.Towers))
+	}
+
+	played := playReplay(t, rec)
+	if worldState(t, played) != worldState(t, original) {
+		t.Error("replay played out differently from the recorded match")
+	}
+}
+
+// strongerDefs are the built-in definitions with stronger darts and
+// tougher spiders, as --towers and --enemies would load them
+func strongerDefs(t *testing.T) *Defs {
+	t.Helper()
+	defs := currentDefs()
+	towers, err := parseTowerDefs([]byte(`[{"id": "dart", "damage": 4}]`), defs.Towers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enemies, err := parseEnemyDefs([]byte(`[{"id": "spider", "healthPerLevel": 12}]`), defs.Enemies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Defs{Towers: towers, Enemies: enemies, Waves: defs.Waves}
+}
+
+func TestReplayKeepsDefs(t *testing.T) {
+	builtin := currentDefs()
+	useDefs(t, strongerDefs(t))
+	original, rec := recordMatch(t, 7)
+
+	// Playback under the built-in definitions uses the recorded ones
+	useDefs(t, builtin)
+	played := playReplay(t, rec)
+	if worldState(t, played) != worldState(t, original) {
+		t.Error("replay played out differently under the definitions it was recorded with")
+	}
+	if played.TowerDefFor(DartTower).Damage != 4 || played.EnemyDefFor(SpiderEnemy).HealthPerLevel != 12 {
+		t.Error("replay isn't played with its definitions")
+	}
+
+	// The same actions under the built-in definitions are a different match
+	fresh := NewWorld(7)
+	runMatch(fresh, matchTicks, scriptedActions, nil)
+	if worldState(t, fresh) == worldState(t, original) {
+		t.Error("definitions made no difference to the match, so the test proves nothing")
+	}
+}
+
+func TestReplayLeavesLoadedDefs(t *testing.T) {
+	rec := NewReplay(7, nil)
+	rec.Defs = strongerDefs(t)
+	rec.Record(0, []Action{{Kind: ActionPlaceTower, X: 5, Y: 5}, {Kind: ActionBegin}})
+	playReplay(t, rec)
+
+	// A match started after playback is played with the loaded definitions
+	live := NewWorld(7)
+	if live.TowerDefFor(DartTower).Damage == 4 || live.EnemyDefFor(SpiderEnemy).HealthPerLevel == 12 {
+		t.Error("replay left its definitions in use for later matches")
+	}
+	if tower, err := live.PlaceTower(DartTower, 5, 5); err != nil || tower.Damage == 4 {
+		t.Errorf("tower built after playback: %+v, %v", tower, err)
+	}
+}
+
+func TestLoadReplayRejectsOldVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.json")
+	if err := os.WriteFile(path, []byte(`{"version": 1, "seed": 3, "events": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadReplay(path); err == nil {
+		t.Error("loaded a replay recorded under older rules")
+	}
+}
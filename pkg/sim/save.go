@@ -0,0 +1,183 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// SaveVersion is the current save file format version. Bump it whenever the
+// layout of World changes and teach migrateSave how to upgrade older files.
+const SaveVersion = 10
+
+// saveFile is the on-disk layout of a saved match
+type saveFile struct {
+	Version int    `json:"version"`
+	World   *World `json:"world"`
+	// Targets maps an enemy's index to the cell of the tower it is
+	// attacking, since tower pointers can't be stored directly
+	Targets map[int]Point `json:"targets,omitempty"`
+	// Sources maps a projectile's index to the cell of the tower that
+	// fired it, for the same reason
+	Sources map[int]Point `json:"sources,omitempty"`
+	// Burners maps a burning enemy's index to the cell of the tower that
+	// set it alight
+	Burners map[int]Point `json:"burners,omitempty"`
+}
+
+// MarshalWorld encodes the complete state of a match
+func MarshalWorld(w *World) ([]byte, error) {
+	save := saveFile{Version: SaveVersion, World: w, Targets: map[int]Point{}, Sources: map[int]Point{}, Burners: map[int]Point{}}
+	for i, enemy := range w.Enemies {
+		if enemy != nil && enemy.TargetTower != nil {
+			save.Targets[i] = enemy.TargetTower.Position
+		}
+		if enemy != nil && enemy.BurnSource != nil {
+			save.Burners[i] = enemy.BurnSource.Position
+		}
+	}
+	for i, proj := range w.Projectiles {
+		if proj != nil && proj.Source != nil {
+			save.Sources[i] = proj.Source.Position
+		}
+	}
+	return json.MarshalIndent(save, "", "  ")
+}
+
+// UnmarshalWorld decodes a match encoded by MarshalWorld, to be played on
+// with the definitions loaded at the time
+func UnmarshalWorld(data []byte) (*World, error) {
+	return unmarshalWorld(data, currentDefs())
+}
+
+// unmarshalWorld decodes a match encoded by MarshalWorld, to be played on
+// with the given definitions
+func unmarshalWorld(data []byte, defs *Defs) (*World, error) {
+	var save saveFile
+	if err := json.Unmarshal(data, &save); err != nil {
+		return nil, err
+	}
+	if save.Version < 1 || save.Version > SaveVersion {
+		return nil, fmt.Errorf("unsupported save version %d", save.Version)
+	}
+	if save.World == nil || save.World.Map == nil || save.World.Rand == nil {
+		return nil, fmt.Errorf("save is missing world state")
+	}
+	migrateSave(&save, defs)
+	if save.World.Wave == nil || len(save.World.Wave.Groups) == 0 {
+		return nil, fmt.Errorf("save is missing the wave in play")
+	}
+	if save.World.Layout == nil || len(save.World.Map.Entrances) == 0 || len(save.World.Map.Exits) == 0 || len(save.World.Map.Routes) == 0 {
+		return nil, fmt.Errorf("save is missing the map layout")
+	}
+
+	w := save.World
+	w.Events = NewEventBus() // Subscribers aren't saved
+	w.Defs = defs
+	w.Map.defs = defs
+	for i, cell := range save.Targets {
+		if i >= 0 && i < len(w.Enemies) && w.Enemies[i] != nil {
+			w.Enemies[i].TargetTower = w.Map.GetTowerAt(cell.X, cell.Y)
+		}
+	}
+	for i, cell := range save.Burners {
+		if i >= 0 && i < len(w.Enemies) && w.Enemies[i] != nil {
+			w.Enemies[i].BurnSource = w.Map.GetTowerAt(cell.X, cell.Y)
+		}
+	}
+	for i, cell := range save.Sources {
+		if i >= 0 && i < len(w.Projectiles) && w.Projectiles[i] != nil {
+			w.Projectiles[i].Source = w.Map.GetTowerAt(cell.X, cell.Y)
+		}
+	}
+	return w, nil
+}
+
+// migrateSave upgrades an older save to the current layout
+func migrateSave(save *saveFile, defs *Defs) {
+	// Version 2 stores the definition of the wave in play; older saves
+	// were made with the built-in waves, so take it from the script
+	if save.Version < 2 && save.World.Wave == nil {
+		save.World.Wave = defs.Waves.Wave(save.World.CurrentWave)
+	}
+	// Version 3 stores the map layout and lists entrances and exits as
+	// spans; older saves were always played on the classic map
+	if save.Version < 3 && save.World.Layout == nil {
+		if layout, err := BuiltinMap(DefaultMap); err == nil {
+			save.World.Layout = layout
+			save.World.Map.Name = layout.Name
+			save.World.Map.Entrances = layout.Entrances
+			save.World.Map.Exits = layout.Exits
+		}
+	}
+	// Version 4 stores the routes across the map; older saves take the
+	// routes their layout implies
+	if save.Version < 4 && len(save.World.Map.Routes) == 0 && save.World.Layout != nil {
+		save.World.Map.Routes = save.World.Layout.routes()
+	}
+	// Version 5 stores the cell each enemy is walking to instead of a
+	// whole path; older enemies carry on to the cell of their target point
+	if save.Version < 5 {
+		for _, enemy := range save.World.Enemies {
+			if enemy != nil {
+				enemy.Next.X, enemy.Next.Y = save.World.Map.CellAt(enemy.TargetX, enemy.TargetY)
+			}
+		}
+	}
+	// Version 6 brought upgrade tiers and made Level count them; older
+	// towers never bought any, so they stand as built at their type's
+	// price, or free if the map put them up
+	if save.Version < 6 {
+		for _, tower := range save.World.Map.Towers {
+			tower.Level = 1
+			prebuilt := false
+			for _, placed := range save.World.Layout.Towers {
+				prebuilt = prebuilt || placed.X == tower.Position.X && placed.Y == tower.Position.Y
+			}
+			if def := defs.tower(tower.Type); def != nil && (tower.Cost != 0 || !prebuilt) {
+				tower.Cost = def.Cost
+			}
+		}
+	}
+	// Versions 7 to 9 give towers chain, then splash and burn, then slow
+	// stats; older towers take those of their type and the tiers they bought
+	if save.Version < 9 {
+		for _, tower := range save.World.Map.Towers {
+			if def := defs.tower(tower.Type); def != nil {
+				tower.ShotEffects = def.effectsAt(tower.Level).shot(save.World.Map.CellSize)
+			}
+		}
+	}
+	// Version 10 gives enemies IDs; number the ones on the field in order
+	if save.Version < 10 {
+		for _, enemy := range save.World.Enemies {
+			if enemy != nil {
+				save.World.LastEnemyID++
+				enemy.ID = save.World.LastEnemyID
+			}
+		}
+	}
+	save.Version = SaveVersion
+}
+
+// SaveWorld writes a match to a file
+func SaveWorld(w *World, path string) error {
+	data, err := MarshalWorld(w)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LoadWorld reads a match saved by SaveWorld
+func LoadWorld(path string) (*World, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	w, err := UnmarshalWorld(data)
+	if err != nil {
+		return nil, fmt.Errorf("loading %s: %w", path, err)
+	}
+	return w, nil
+}
@@ -0,0 +1,148 @@
+package sim
+
+import (
+	"testing"
+)
+
+// settleTicks is how long restored worlds are run side by side with the
+// world they were saved from
+const settleTicks = 900
+
+// ghoulRush is a wave script whose first wave is tower-attacking ghouls
+// and slow-shy snakes, so a save soon has every pointer a save stores
+const ghoulRush = `{"waves": [{"delay": 0.5, "groups": [
+	{"enemy": "ghoul", "count": 10, "minSpacing": 0.4, "maxSpacing": 0.8},
+	{"enemy": "snake", "count": 10, "minSpacing": 0.4, "maxSpacing": 0.8}
+]}]}`
+
+// busyWorld plays the scripted match against a ghoul rush until a tower is
+// under attack, a shot is in flight and enemies are burning and slowed
+func busyWorld(t *testing.T) *World {
+	t.Helper()
+	waves, err := parseWaves([]byte(ghoulRush))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Saves don't carry definitions, so the waves are loaded for the
+	// restored worlds too
+	useDefs(t, &Defs{Towers: towerDefs, Enemies: enemyDefs, Waves: waves})
+
+	w := NewWorld(11)
+	w.Money = 1000 // Enough for the scripted upgrades to go through
+	for w.Frame < matchTicks {
+		w.Tick(scriptedActions(w.Frame))
+		attacked, burning, slowed, inFlight := false, false, false, false
+		for _, enemy := range w.Enemies {
+			attacked = attacked || enemy.TargetTower != nil
+			burning = burning || enemy.BurnSource != nil
+			slowed = slowed || enemy.Slow > 0
+		}
+		for _, proj := range w.Projectiles {
+			inFlight = inFlight || proj.Source != nil
+		}
+		if attacked && burning && slowed && inFlight {
+			return w
+		}
+	}
+	t.Fatal("the match never had a tower under attack, a shot in flight and enemies burning and slowed at once")
+	return nil
+}
+
+// cellOf returns the cell of a tower, or a cell off the map for none
+func cellOf(t *Tower) Point {
+	if t == nil {
+		return Point{-1, -1}
+	}
+	return t.Position
+}
+
+func TestSaveRoundTrip(t *testing.T) {
+	original := busyWorld(t)
+	data, err := MarshalWorld(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := UnmarshalWorld(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if worldState(t, loaded) != worldState(t, original) {
+		t.Fatal("loaded world doesn't match the one saved")
+	}
+
+	// Towers are restored by their cell, as the towers of the loaded world
+	for i, enemy := range loaded.Enemies {
+		was := original.Enemies[i]
+		if cellOf(enemy.TargetTower) != cellOf(was.TargetTower) || cellOf(enemy.BurnSource) != cellOf(was.BurnSource) {
+			t.Errorf("enemy %d lost track of the towers it attacks or burns from", i)
+		}
+		for _, tower := range []*Tower{enemy.TargetTower, enemy.BurnSource} {
+			if tower != nil && loaded.Map.GetTowerAt(tower.Position.X, tower.Position.Y) != tower {
+				t.Errorf("enemy %d points at a tower that isn't on the loaded map", i)
+			}
+		}
+	}
+	for i, proj := range loaded.Projectiles {
+		if cellOf(proj.Source) != cellOf(original.Projectiles[i].Source) {
+			t.Errorf("projectile %d lost the tower that fired it", i)
+		}
+	}
+	if tower := loaded.Map.GetTowerAt(5, 5); tower == nil || tower.Target != TargetFirst {
+		t.Error("target priority wasn't restored")
+	}
+
+	// Both play on exactly alike, kill credit and all
+	runMatch(original, original.Frame+settleTicks, scriptedActions, nil)
+	runMatch(loaded, loaded.Frame+settleTicks, scriptedActions, nil)
+	if worldState(t, loaded) != worldState(t, original) {
+		t.Error("loaded world played on differently from the one saved")
+	}
+}
+
+func TestLoadVersion1Save(t *testing.T) {
+	w, err := LoadWorld("testdata/save_v1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w.Wave == nil || w.Layout == nil || w.Layout.Name != "Classic" || len(w.Map.Routes) == 0 {
+		t.Fatal("old save wasn't given the wave, map layout and routes it played with")
+	}
+	for i, enemy := range w.Enemies {
+		if enemy.ID != i+1 {
+			t.Errorf("enemy %d was given ID %d", i, enemy.ID)
+		}
+		cellX, cellY := w.Map.CellAt(enemy.X, enemy.Y)
+		if abs(enemy.Next.X-cellX)+abs(enemy.Next.Y-cellY) > 1 || !w.Map.Passable(enemy.Next.X, enemy.Next.Y, enemy.CanFly) {
+			t.Errorf("enemy %d on %d,%d walks to %v", i, cellX, cellY, enemy.Next)
+		}
+	}
+
+	// Towers stand as built, with the effects of their type
+	for _, tower := range w.Map.Towers {
+		def := w.TowerDefFor(tower.Type)
+		if tower.Level != 1 || tower.Cost != def.Cost {
+			t.Errorf("%s tower is level %d and cost %d", def.Name, tower.Level, tower.Cost)
+		}
+		if tower.ShotEffects != def.effectsAt(1).shot(w.Map.CellSize) {
+			t.Errorf("%s tower has effects %+v", def.Name, tower.ShotEffects)
+		}
+	}
+	if tower := w.Map.GetTowerAt(5, 5); tower == nil || tower.Type != LightningTower || tower.Chain == 0 {
+		t.Error("lightning tower didn't get its chain")
+	}
+
+	// The match plays on, and saves and loads like any other
+	runMatch(w, w.Frame+settleTicks, func(int64) []Action { return nil }, nil)
+	data, err := MarshalWorld(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := UnmarshalWorld(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if worldState(t, again) != worldState(t, w) {
+		t.Error("migrated save didn't survive another round trip")
+	}
+}
@@ -0,0 +1,132 @@
+package sim
+
+import "fmt"
+
+// TargetPriority is how a tower picks between the enemies in its range
+type TargetPriority int
+
+const (
+	TargetClosest   TargetPriority = iota // Nearest to the tower
+	TargetFirst                           // Closest to its exit along its path
+	TargetLast                            // Furthest from its exit along its path
+	TargetStrongest                       // Most health left
+	TargetWeakest                         // Least health left
+	TargetFastest                         // Moving fastest right now, slows and ground included
+	TargetUnfrozen                        // Enemies not slowed or rooted yet; freeze towers only
+	TargetBoss                            // Boss enemies
+)
+
+// targetNames are the names priorities are stored under in saves and
+// replays and shown to the player
+var targetNames = map[TargetPriority]string{
+	TargetClosest:   "closest",
+	TargetFirst:     "first",
+	TargetLast:      "last",
+	TargetStrongest: "strongest",
+	TargetWeakest:   "weakest",
+	TargetFastest:   "fastest",
+	TargetUnfrozen:  "unfrozen",
+	TargetBoss:      "boss",
+}
+
+// String returns the name of a priority
+func (p TargetPriority) String() string {
+	if name, ok := targetNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("TargetPriority(%d)", int(p))
+}
+
+// MarshalText stores a priority by name
+func (p TargetPriority) MarshalText() ([]byte, error) {
+	name, ok := targetNames[p]
+	if !ok {
+		return nil, fmt.Errorf("unknown target priority %d", p)
+	}
+	return []byte(name), nil
+}
+
+// UnmarshalText reads a priority stored by name
+func (p *TargetPriority) UnmarshalText(text []byte) error {
+	for priority, name := range targetNames {
+		if name == string(text) {
+			*p = priority
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown target priority %q", text)
+}
+
+// TargetPriorities lists the priorities a tower type can use, in the order
+// the player cycles through them
+func (w *World) TargetPriorities(towerType TowerType) []TargetPriority {
+	priorities := []TargetPriority{TargetClosest, TargetFirst, TargetLast, TargetStrongest, TargetWeakest, TargetFastest}
+	if def := w.TowerDefFor(towerType); def != nil && def.ProjType == FreezeProjectile {
+		priorities = append(priorities, TargetUnfrozen)
+	}
+	return append(priorities, TargetBoss)
+}
+
+// NextTargetPriority returns the priority after a tower's own in the
+// order TargetPriorities lists them
+func (w *World) NextTargetPriority(t *Tower) TargetPriority {
+	priorities := w.TargetPriorities(t.Type)
+	for i, priority := range priorities {
+		if priority == t.Target {
+			return priorities[(i+1)%len(priorities)]
+		}
+	}
+	return priorities[0]
+}
+
+// candidate is an enemy a tower could shoot, with what the priorities
+// weigh it by
+type candidate struct {
+	enemy     *Enemy
+	dist      float64 // Distance from the tower
+	remaining float64 // Cost of the rest of its way to its exit
+	speed     float64 // Distance it moves this tick
+	boss      bool    // Whether it is of a boss type
+}
+
+// newCandidate weighs an enemy at a distance from a tower
+func newCandidate(enemy *Enemy, dist float64, gameMap *GameMap) candidate {
+	def := gameMap.defs.enemy(enemy.Type)
+	return candidate{enemy: enemy, dist: dist, remaining: enemy.remaining(gameMap), speed: enemy.currentSpeed(gameMap), boss: def != nil && def.Boss}
+}
+
+// prefers reports whether a tower with this priority would rather shoot a
+// than b. Ties go to the closer enemy.
+func (p TargetPriority) prefers(a, b candidate) bool {
+	switch p {
+	case TargetFirst:
+		if a.remaining != b.remaining {
+			return a.remaining < b.remaining
+		}
+	case TargetLast:
+		if a.remaining != b.remaining {
+			return a.remaining > b.remaining
+		}
+	case TargetStrongest:
+		if a.enemy.Health != b.enemy.Health {
+			return a.enemy.Health > b.enemy.Health
+		}
+	case TargetWeakest:
+		if a.enemy.Health != b.enemy.Health {
+			return a.enemy.Health < b.enemy.Health
+		}
+	case TargetFastest:
+		if a.speed != b.speed {
+			return a.speed > b.speed
+		}
+	case TargetUnfrozen:
+		if aChilled, bChilled := a.enemy.chilled(), b.enemy.chilled(); aChilled != bChilled {
+			return !aChilled
+		}
+	case TargetBoss:
+		if a.boss != b.boss {
+			return a.boss
+		}
+	}
+	return a.dist < b.dist
+}
@@ -0,0 +1,67 @@
+package sim
+
+import "testing"
+
+// wallMap builds a map whose path runs up the left of a wall, over the
+// top and down the right to an exit beside the entrance
+func wallMap(t *testing.T) *GameMap {
+	t.Helper()
+	return gridMap(t,
+		".......",
+		"...#...",
+		"...#...",
+		"...#...",
+		"...#...",
+	)
+}
+
+// enemyAt puts a spider on a cell of a map, walking to another
+func enemyAt(m *GameMap, cell, next Point) *Enemy {
+	x, y := m.CellCenter(cell.X, cell.Y)
+	def := m.defs.enemy(SpiderEnemy)
+	e := NewEnemy(x, y, m.CellSize, def, 1, def)
+	e.Next = next
+	return e
+}
+
+func TestTargetFirstGoesByWayLeft(t *testing.T) {
+	m := wallMap(t)
+
+	// The enemy left of the wall is right beside the exit as the crow
+	// flies, but has the whole way round the wall still to go
+	behind := enemyAt(m, Point{2, 4}, Point{2, 3})
+	ahead := enemyAt(m, Point{4, 1}, Point{4, 2})
+	a, b := newCandidate(behind, 10, m), newCandidate(ahead, 20, m)
+	if a.remaining <= b.remaining {
+		t.Fatalf("enemy behind the wall has %.1f left, the one past it %.1f", a.remaining, b.remaining)
+	}
+
+	if !TargetFirst.prefers(b, a) {
+		t.Error("first didn't pick the enemy closest to the exit along the path")
+	}
+	if !TargetLast.prefers(a, b) {
+		t.Error("last didn't pick the enemy furthest from the exit along the path")
+	}
+}
+
+func TestTargetFastestGoesByCurrentSpeed(t *testing.T) {
+	m := wallMap(t)
+	slowed := enemyAt(m, Point{0, 0}, Point{1, 0})
+	slowed.Speed *= 2
+	slowed.Slow, slowed.SlowTimer = 0.75, 60
+	plain := enemyAt(m, Point{1, 0}, Point{2, 0})
+	rooted := enemyAt(m, Point{2, 0}, Point{3, 0})
+	rooted.Speed *= 4
+	rooted.FrozenTimer = 30
+
+	fastSlowed, fastPlain, fastRooted := newCandidate(slowed, 10, m), newCandidate(plain, 20, m), newCandidate(rooted, 5, m)
+	if fastRooted.speed != 0 {
+		t.Errorf("rooted enemy moves %.2f a tick", fastRooted.speed)
+	}
+	if !TargetFastest.prefers(fastPlain, fastSlowed) {
+		t.Error("a slowed enemy counted as faster than one with less base speed and no slow")
+	}
+	if !TargetFastest.prefers(fastPlain, fastRooted) {
+		t.Error("a rooted enemy counted as moving")
+	}
+}
@@ -0,0 +1,162 @@
+package sim
+
+import (
+	"math"
+)
+
+// TowerType represents different types of towers
+type TowerType int
+
+const (
+	DartTower TowerType = iota
+	BulletTower
+	LightningTower
+	FlameTower
+	FreezeTower
+	ForkTower
+)
+
+// Tower represents a defensive tower
+type Tower struct {
+	Position        Point
+	Type            TowerType
+	Damage          float64
+	AttackRange     float64
+	FireRate        float64
+	Cost            int   // Points paid for the tower and its upgrades
+	ReadyAt         int64 // Simulation tick at which the tower may fire again
+	Level           int   // 1 as built, one more for each upgrade bought
+	CanFireDiagonal bool
+	Health          float64
+	MaxHealth       float64
+	UnderAttack     int            // Ticks left on the "under attack" flash
+	Kills           int            // Enemies finished off by its shots
+	DamageDealt     float64        // Health its shots have taken off enemies
+	Target          TargetPriority // Which enemy in range it shoots first
+	ShotEffects                    // What its shots do besides damage
+}
+
+// Update picks a target and returns any projectiles fired this tick
+func (t *Tower) Update(w *World) []*Projectile {
+	// Fade the under-attack flash
+	if t.UnderAttack > 0 {
+		t.UnderAttack--
+	}
+
+	def := w.TowerDefFor(t.Type)
+	var best candidate
+
+	// Calculate tower center position
+	towerX, towerY := w.Map.CellCenter(t.Position.X, t.Position.Y)
+
+	for _, enemy := range w.Enemies {
+		if enemy == nil || enemy.CanFly && !def.AntiAir {
+			continue
+		}
+
+		dx := enemy.X - towerX
+		dy := enemy.Y - towerY
+		dist := math.Sqrt(dx*dx + dy*dy)
+
+		if dist >= t.AttackRange {
+			continue
+		}
+
+		if !t.CanFireDiagonal {
+			angle := math.Atan2(dy, dx) * 180 / math.Pi
+			if angle < 0 {
+				angle += 360
+			}
+
+			isCardinal := false
+			for _, cardinal := range []float64{0, 90, 180, 270} {
+				angleDiff := math.Abs(angle - cardinal)
+				if angleDiff <= 22.5 || angleDiff >= 337.5 {
+					isCardinal = true
+					break
+				}
+			}
+
+			if !isCardinal {
+				continue
+			}
+		}
+
+		if next := newCandidate(enemy, dist, w.Map); best.enemy == nil || t.Target.prefers(next, best) {
+			best = next
+		}
+	}
+
+	if best.enemy != nil && t.canShoot(w.Clock) {
+		proj := NewProjectile(
+			towerX,
+			towerY,
+			best.enemy.X,
+			best.enemy.Y,
+			def.ProjType,
+			t.Damage,
+		)
+		proj.AntiAir = def.AntiAir
+		proj.Source = t
+		proj.ShotEffects = t.ShotEffects
+
+		t.ReadyAt = w.Clock + t.reloadTicks()
+		w.Events.Publish(Event{Kind: EventTowerFired, Tower: t})
+		return []*Projectile{proj}
+	}
+
+	return nil
+}
+
+// canShoot reports whether the tower has reloaded at the given tick
+func (t *Tower) canShoot(now int64) bool {
+	return now >= t.ReadyAt
+}
+
+// reloadTicks converts the fire rate (shots per second) into ticks between shots
+func (t *Tower) reloadTicks() int64 {
+	return int64(math.Round(TicksPerSecond / t.FireRate))
+}
+
+// NextTier returns the upgrade a tower can buy next, or nil once it has
+// bought them all
+func (w *World) NextTier(t *Tower) *TowerTier {
+	def := w.TowerDefFor(t.Type)
+	if def == nil || t.Level < 1 || t.Level > len(def.Tiers) {
+		return nil
+	}
+	return &def.Tiers[t.Level-1]
+}
+
+func (t *Tower) GetPosition() Point {
+	return t.Position
+}
+
+// NewTower creates a tower from its definition on a map with the given cell size
+func NewTower(def *TowerDef, x, y, cellSize int) *Tower {
+	if def == nil {
+		return nil
+	}
+
+	return &Tower{
+		Position:        Point{x, y},
+		Type:            def.Type,
+		Level:           1,
+		Damage:          def.Damage,
+		AttackRange:     def.Range * float64(cellSize),
+		FireRate:        def.FireRate,
+		CanFireDiagonal: def.Diagonal,
+		Cost:            def.Cost,
+		Health:          def.Health,
+		MaxHealth:       def.Health,
+		ShotEffects:     def.effectsAt(1).shot(cellSize),
+	}
+}
+
+func (t *Tower) TakeDamage(damage float64) bool {
+	t.Health = math.Max(0, t.Health-damage) // Prevent negative health
+	t.UnderAttack = TicksPerSecond / 2      // Flash duration (0.5 seconds)
+
+	// Return true if tower is destroyed
+	return t.Health <= 0
+}
@@ -0,0 +1,165 @@
+package sim
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"os"
+)
+
+//go:embed data/towers.json
+var builtinTowerDefs []byte
+
+// TowerDef holds the stats and look of a tower type
+type TowerDef struct {
+	Type        TowerType      `json:"-"`
+	ID          string         `json:"id"`          // Name used in definition files
+	Name        string         `json:"name"`        // Name shown to the player
+	Cost        int            `json:"cost"`        // Price in points
+	Damage      float64        `json:"damage"`      // Damage per projectile
+	Range       float64        `json:"range"`       // Attack range in cells
+	FireRate    float64        `json:"fireRate"`    // Shots per second
+	Diagonal    bool           `json:"diagonal"`    // Whether it can fire at diagonal targets
+	AntiAir     bool           `json:"antiAir"`     // Whether it can hit flying enemies
+	Projectile  string         `json:"projectile"`  // Projectile id: dart, bullet, lightning, flame or freeze
+	Health      float64        `json:"health"`      // Hit points
+	Color       color.RGBA     `json:"color"`       // Base color used for effects
+	Sprite      string         `json:"sprite"`      // Name of the pixel art drawn for the tower
+	SpriteColor color.RGBA     `json:"spriteColor"` // Main sprite color
+	DetailColor color.RGBA     `json:"detailColor"` // Sprite detail color
+	Limit       int            `json:"limit"`       // Most towers of this type standing at once; 0 for no limit
+	Tiers       []TowerTier    `json:"tiers"`       // Upgrades a built tower can buy, in order
+	EffectStats                // What shots do besides damage
+	ProjType    ProjectileType `json:"-"` // Parsed from Projectile
+}
+
+// TowerTier is one upgrade of a tower. Its stats are added to the tower's.
+type TowerTier struct {
+	Cost        int     `json:"cost"`     // Price in points
+	Damage      float64 `json:"damage"`   // Extra damage per projectile
+	Range       float64 `json:"range"`    // Extra range in cells
+	FireRate    float64 `json:"fireRate"` // Extra shots per second
+	EffectStats         // Added to the tower's effect stats
+}
+
+// towerIDs maps definition file ids to tower types
+var towerIDs = map[string]TowerType{
+	"dart":      DartTower,
+	"bullet":    BulletTower,
+	"lightning": LightningTower,
+	"flame":     FlameTower,
+	"freeze":    FreezeTower,
+	"fork":      ForkTower,
+}
+
+// projectileIDs maps definition file ids to projectile types
+var projectileIDs = map[string]ProjectileType{
+	"dart":      DartProjectile,
+	"bullet":    BulletProjectile,
+	"lightning": LightningProjectile,
+	"flame":     FlameProjectile,
+	"freeze":    FreezeProjectile,
+}
+
+// towerDefs holds the definition of every tower type new matches are
+// played with, indexed by type
+var towerDefs []*TowerDef
+
+func init() {
+	defs, err := parseTowerDefs(builtinTowerDefs, nil)
+	if err != nil {
+		panic(fmt.Sprintf("built-in tower definitions: %v", err))
+	}
+	towerDefs = defs
+}
+
+// LoadTowerDefs rebalances towers from a file on disk. The file uses the
+// same layout as the built-in table; each entry is matched by id and only
+// the fields it lists are changed. Load it before starting a match, since
+// replays and saves assume the same stats.
+func LoadTowerDefs(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	defs, err := parseTowerDefs(data, towerDefs)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	towerDefs = defs
+	return nil
+}
+
+// parseTowerDefs applies a definition table on top of existing definitions
+func parseTowerDefs(data []byte, base []*TowerDef) ([]*TowerDef, error) {
+	defs, err := parseDefs(data, base, towerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, def := range defs {
+		if def == nil {
+			continue
+		}
+		def.Type = TowerType(i)
+		if err := def.validate(); err != nil {
+			return nil, fmt.Errorf("tower %q: %w", def.ID, err)
+		}
+	}
+	return defs, nil
+}
+
+// validate checks a definition for values the simulation can't use
+func (d *TowerDef) validate() error {
+	projType, ok := projectileIDs[d.Projectile]
+	if !ok {
+		return fmt.Errorf("unknown projectile %q", d.Projectile)
+	}
+	d.ProjType = projType
+
+	switch {
+	case d.Cost < 0:
+		return fmt.Errorf("negative cost")
+	case d.Damage < 0:
+		return fmt.Errorf("negative damage")
+	case d.Range <= 0:
+		return fmt.Errorf("range must be positive")
+	case d.FireRate <= 0:
+		return fmt.Errorf("fire rate must be positive")
+	case d.Health <= 0:
+		return fmt.Errorf("health must be positive")
+	case d.Limit < 0:
+		return fmt.Errorf("negative build limit")
+	}
+	if err := d.EffectStats.validate(); err != nil {
+		return err
+	}
+	for i, tier := range d.Tiers {
+		if tier.Cost < 0 || tier.Damage < 0 || tier.Range < 0 || tier.FireRate < 0 || tier.EffectStats.negative() {
+			return fmt.Errorf("tier %d: negative cost or stats", i+1)
+		}
+		if err := d.effectsAt(i + 2).validate(); err != nil {
+			return fmt.Errorf("tier %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON reads a tier in full. Decoding a list writes over the
+// elements already in it, so without this a tier list in an override file
+// would keep whatever stats its entries leave out.
+func (t *TowerTier) UnmarshalJSON(data []byte) error {
+	type plain TowerTier // Without the method, to avoid recursing
+	var tier plain
+	if err := json.Unmarshal(data, &tier); err != nil {
+		return err
+	}
+	*t = TowerTier(tier)
+	return nil
+}
+
+// ownSlices gives the definition its own copy of the tier list
+func (d *TowerDef) ownSlices() {
+	d.Tiers = append([]TowerTier(nil), d.Tiers...)
+}
@@ -0,0 +1,56 @@
+// Package sim contains the Argent Tower simulation core. It has no ebiten
+// dependency: it advances one tick at a time from explicit player actions,
+// so it can run headless for tests, bots and balance runs.
+package sim
+
+// Point represents a position on the grid
+type Point struct {
+	X, Y int
+}
+
+// TerrainType represents different types of terrain
+type TerrainType int
+
+const (
+	Empty          TerrainType = iota
+	TowerPlacement             // Occupied by a tower
+	Rock                       // Nothing can cross it or build on it
+	NoBuild                    // Open ground that towers can't be built on
+	Water                      // Only flyers can cross it; nothing can build on it
+	Mud                        // Slows ground units, who avoid it when they can
+)
+
+// Mud rules
+const (
+	MudSpeed = 0.5 // Speed multiplier for ground units wading through mud
+	MudCost  = 3   // Path cost of a mud cell; other open cells cost 1
+)
+
+// TicksPerSecond is the number of simulation ticks in one second of game time.
+// Every timer in the simulation counts ticks, never wall-clock time.
+const TicksPerSecond = 60
+
+// GameState represents the current state of the game
+type GameState int
+
+const (
+	BuildState GameState = iota
+	PlayState
+	PausedState
+	GameOverState
+	EditorState // Editing the map before a match; see OpenEditor
+)
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
@@ -0,0 +1,263 @@
+package sim
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+//go:embed data/waves.json
+var builtinWaves []byte
+
+// WaveScript describes a campaign: a list of scripted waves, optionally
+// followed by a generator that keeps producing waves for endless play
+type WaveScript struct {
+	Waves   []WaveDef      `json:"waves"`
+	Endless *WaveGenerator `json:"endless,omitempty"`
+}
+
+// WaveDef describes a single wave
+type WaveDef struct {
+	Name      string        `json:"name,omitempty"`    // Shown in the HUD; defaults to the palette enemy
+	Boss      bool          `json:"boss,omitempty"`    // Announced in advance and shown as a boss wave
+	Delay     float64       `json:"delay"`             // Seconds before the first spawn
+	Palette   string        `json:"palette,omitempty"` // Enemy id whose colors every enemy wears; defaults to the first group's
+	Groups    []WaveGroup   `json:"groups"`            // Spawned one after the other
+	Modifiers WaveModifiers `json:"modifiers"`
+}
+
+// WaveGroup is a run of identical enemies within a wave
+type WaveGroup struct {
+	Enemy      string  `json:"enemy"`           // Enemy id
+	Count      int     `json:"count"`           // How many to spawn
+	Level      int     `json:"level,omitempty"` // Enemy level; 0 uses the wave number
+	MinSpacing float64 `json:"minSpacing"`      // Shortest gap in seconds before the next spawn
+	MaxSpacing float64 `json:"maxSpacing"`      // Longest gap; the actual gap is picked at random
+	Route      int     `json:"route,omitempty"` // Map route to follow, counting from 1; 0 picks one at random
+}
+
+// WaveModifiers adjust every enemy spawned in a wave
+type WaveModifiers struct {
+	Health        float64 `json:"health,omitempty"`        // Health multiplier; 0 leaves health unchanged
+	Speed         float64 `json:"speed,omitempty"`         // Speed multiplier; 0 leaves speed unchanged
+	OffTypeChance float64 `json:"offTypeChance,omitempty"` // Chance that a spawn after the first is a random other non-boss enemy
+}
+
+// WaveGenerator builds endless waves once the scripted ones run out. Normal
+// waves cycle through enemy types and grow by CountPerWave each wave; every
+// BossEvery waves from wave FirstBoss on is a boss wave instead, after which
+// the cycle starts over.
+type WaveGenerator struct {
+	Cycle        []string      `json:"cycle"`        // Enemy ids for normal waves, in order
+	BaseCount    int           `json:"baseCount"`    // Enemies in a normal wave before growth
+	CountPerWave int           `json:"countPerWave"` // Extra enemies per wave number
+	Delay        float64       `json:"delay"`        // Seconds before the first spawn of a wave
+	DelayStep    float64       `json:"delayStep"`    // Seconds the delay shrinks per wave
+	MinDelay     float64       `json:"minDelay"`     // Shortest delay allowed
+	MinSpacing   float64       `json:"minSpacing"`
+	MaxSpacing   float64       `json:"maxSpacing"`
+	Modifiers    WaveModifiers `json:"modifiers"`
+	Boss         string        `json:"boss,omitempty"` // Enemy id of the boss; empty for no boss waves
+	BossCount    int           `json:"bossCount"`
+	FirstBoss    int           `json:"firstBoss"` // Wave number of the first boss wave, counting from 1
+	BossEvery    int           `json:"bossEvery"`
+}
+
+// waveScript is the campaign every new match plays
+var waveScript *WaveScript
+
+func init() {
+	script, err := parseWaves(builtinWaves)
+	if err != nil {
+		panic(fmt.Sprintf("built-in waves: %v", err))
+	}
+	waveScript = script
+}
+
+// LoadWaves replaces the campaign with one read from a file. Load it before
+// starting a match, since replays and saves assume the same waves.
+func LoadWaves(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	script, err := parseWaves(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	waveScript = script
+	return nil
+}
+
+// parseWaves decodes and checks a wave script
+func parseWaves(data []byte) (*WaveScript, error) {
+	var script WaveScript
+	if err := json.Unmarshal(data, &script); err != nil {
+		return nil, err
+	}
+	if len(script.Waves) == 0 && script.Endless == nil {
+		return nil, fmt.Errorf("no waves")
+	}
+	for i := range script.Waves {
+		if err := script.Waves[i].validate(); err != nil {
+			return nil, fmt.Errorf("wave %d: %w", i+1, err)
+		}
+	}
+	if script.Endless != nil {
+		if err := script.Endless.validate(); err != nil {
+			return nil, fmt.Errorf("endless: %w", err)
+		}
+	}
+	return &script, nil
+}
+
+// Wave returns the definition of a wave, counting from 0, or nil once the
+// campaign is over
+func (s *WaveScript) Wave(index int) *WaveDef {
+	if index < len(s.Waves) {
+		wave := s.Waves[index]
+		return &wave
+	}
+	if s.Endless != nil {
+		return s.Endless.wave(index, len(s.Waves))
+	}
+	return nil
+}
+
+// wave generates the wave at index; start is the index of the first
+// generated wave
+func (g *WaveGenerator) wave(index, start int) *WaveDef {
+	delay := max(secondsToTicks(g.MinDelay), secondsToTicks(g.Delay)-index*secondsToTicks(g.DelayStep))
+	wave := &WaveDef{
+		Delay:     float64(delay) / TicksPerSecond,
+		Modifiers: g.Modifiers,
+	}
+
+	if g.isBoss(index) {
+		wave.Boss = true
+		wave.Groups = []WaveGroup{{Enemy: g.Boss, Count: g.BossCount, MinSpacing: g.MinSpacing, MaxSpacing: g.MaxSpacing}}
+		return wave
+	}
+
+	// Count waves since the cycle last started over
+	cycleStart := start
+	for i := index - 1; i >= start; i-- {
+		if g.isBoss(i) {
+			cycleStart = i + 1
+			break
+		}
+	}
+	enemy := g.Cycle[(index-cycleStart)%len(g.Cycle)]
+	count := g.BaseCount + g.CountPerWave*index
+	wave.Groups = []WaveGroup{{Enemy: enemy, Count: count, MinSpacing: g.MinSpacing, MaxSpacing: g.MaxSpacing}}
+	return wave
+}
+
+// isBoss reports whether the generated wave at index is a boss wave
+func (g *WaveGenerator) isBoss(index int) bool {
+	number := index + 1
+	return g.Boss != "" && g.BossEvery > 0 && number >= g.FirstBoss && (number-g.FirstBoss)%g.BossEvery == 0
+}
+
+// validate checks a wave for values the simulation can't use
+func (w *WaveDef) validate() error {
+	if len(w.Groups) == 0 {
+		return fmt.Errorf("no groups")
+	}
+	if w.Delay < 0 {
+		return fmt.Errorf("negative delay")
+	}
+	if _, ok := enemyIDs[w.Palette]; w.Palette != "" && !ok {
+		return fmt.Errorf("unknown palette %q", w.Palette)
+	}
+	for i, group := range w.Groups {
+		if _, ok := enemyIDs[group.Enemy]; !ok {
+			return fmt.Errorf("group %d: unknown enemy %q", i+1, group.Enemy)
+		}
+		if group.Count <= 0 {
+			return fmt.Errorf("group %d: count must be positive", i+1)
+		}
+		if group.Level < 0 || group.Route < 0 {
+			return fmt.Errorf("group %d: negative level or route", i+1)
+		}
+		if group.MinSpacing < 0 || group.MaxSpacing < group.MinSpacing {
+			return fmt.Errorf("group %d: bad spacing %g-%g", i+1, group.MinSpacing, group.MaxSpacing)
+		}
+	}
+	return nil
+}
+
+// validate checks a generator for values the simulation can't use
+func (g *WaveGenerator) validate() error {
+	if len(g.Cycle) == 0 {
+		return fmt.Errorf("empty cycle")
+	}
+	for _, id := range g.Cycle {
+		if _, ok := enemyIDs[id]; !ok {
+			return fmt.Errorf("unknown enemy %q", id)
+		}
+	}
+	if _, ok := enemyIDs[g.Boss]; g.Boss != "" && (!ok || g.BossCount <= 0) {
+		return fmt.Errorf("bad boss %q", g.Boss)
+	}
+	if g.BaseCount <= 0 || g.CountPerWave < 0 {
+		return fmt.Errorf("bad wave size")
+	}
+	if g.MinSpacing < 0 || g.MaxSpacing < g.MinSpacing {
+		return fmt.Errorf("bad spacing %g-%g", g.MinSpacing, g.MaxSpacing)
+	}
+	return nil
+}
+
+// Count returns the number of enemies in the wave
+func (w *WaveDef) Count() int {
+	count := 0
+	for _, group := range w.Groups {
+		count += group.Count
+	}
+	return count
+}
+
+// Lead returns the enemy type whose colors the wave wears
+func (w *WaveDef) Lead() EnemyType {
+	if lead, ok := enemyIDs[w.Palette]; ok {
+		return lead
+	}
+	return enemyIDs[w.Groups[0].Enemy]
+}
+
+// Title returns the name of the wave shown to the player in a match played
+// with the given definitions
+func (w *WaveDef) Title(defs *Defs) string {
+	switch {
+	case w.Name != "":
+		return w.Name
+	case w.Boss:
+		return "BOSS"
+	}
+	if def := defs.enemy(w.Lead()); def != nil {
+		return def.Name + "s"
+	}
+	return ""
+}
+
+// group returns the group the nth enemy of the wave belongs to
+func (w *WaveDef) group(spawned int) *WaveGroup {
+	for i := range w.Groups {
+		if spawned < w.Groups[i].Count {
+			return &w.Groups[i]
+		}
+		spawned -= w.Groups[i].Count
+	}
+	return &w.Groups[len(w.Groups)-1]
+}
+
+// spacing picks the number of ticks to wait before the next spawn
+func (g *WaveGroup) spacing(rng *RNG) int {
+	minTicks, maxTicks := secondsToTicks(g.MinSpacing), secondsToTicks(g.MaxSpacing)
+	if maxTicks <= minTicks {
+		return minTicks
+	}
+	return minTicks + rng.Intn(maxTicks-minTicks)
+}
@@ -0,0 +1,89 @@
+package sim
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseWaves(t *testing.T) {
+	if _, err := parseWaves(builtinWaves); err != nil {
+		t.Fatalf("built-in waves: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		script string
+		want   string // Part of the error
+	}{
+		{"not json", `{"waves": [`, "unexpected end"},
+		{"no waves", `{"waves": []}`, "no waves"},
+		{"no groups", `{"waves": [{"delay": 1, "groups": []}]}`, "wave 1: no groups"},
+		{"negative delay", `{"waves": [{"delay": -1, "groups": [{"enemy": "spider", "count": 1}]}]}`, "negative delay"},
+		{"unknown enemy", `{"waves": [{"groups": [{"enemy": "dragon", "count": 1}]}]}`, `unknown enemy "dragon"`},
+		{"unknown palette", `{"waves": [{"palette": "dragon", "groups": [{"enemy": "spider", "count": 1}]}]}`, `unknown palette "dragon"`},
+		{"no count", `{"waves": [{"groups": [{"enemy": "spider"}]}]}`, "count must be positive"},
+		{"bad spacing", `{"waves": [{"groups": [{"enemy": "spider", "count": 1, "minSpacing": 2, "maxSpacing": 1}]}]}`, "bad spacing"},
+		{"second wave bad", `{"waves": [
+			{"groups": [{"enemy": "spider", "count": 1}]},
+			{"groups": [{"enemy": "spider", "count": 1, "level": -1}]}
+		]}`, "wave 2: group 1: negative level"},
+		{"endless without a cycle", `{"endless": {"baseCount": 1}}`, "endless: empty cycle"},
+		{"endless with an unknown boss", `{"endless": {"cycle": ["spider"], "baseCount": 1, "boss": "dragon", "bossCount": 1}}`, `bad boss "dragon"`},
+		{"endless with no enemies", `{"endless": {"cycle": ["spider"]}}`, "bad wave size"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseWaves([]byte(tt.script))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want an error with %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEndlessWaves(t *testing.T) {
+	script, err := parseWaves([]byte(`{
+		"waves": [{"groups": [{"enemy": "ghoul", "count": 3}]}],
+		"endless": {
+			"cycle": ["spider", "snake", "hawk"],
+			"baseCount": 10, "countPerWave": 2,
+			"delay": 2, "delayStep": 0.5, "minDelay": 1,
+			"boss": "blob", "bossCount": 1, "firstBoss": 4, "bossEvery": 3
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		enemy string
+		count int
+		boss  bool
+		delay float64
+	}{
+		{"ghoul", 3, false, 0},     // Scripted
+		{"spider", 12, false, 1.5}, // Generated waves start the cycle
+		{"snake", 14, false, 1},
+		{"blob", 1, true, 1},
+		{"spider", 18, false, 1}, // The cycle starts over after a boss
+		{"snake", 20, false, 1},
+		{"blob", 1, true, 1},
+		{"spider", 24, false, 1},
+	}
+	for i, tt := range tests {
+		wave := script.Wave(i)
+		if wave == nil {
+			t.Fatalf("wave %d missing", i+1)
+		}
+		group := wave.Groups[0]
+		if group.Enemy != tt.enemy || wave.Count() != tt.count || wave.Boss != tt.boss || wave.Delay != tt.delay {
+			t.Errorf("wave %d is %d %s (boss %v) after %gs, want %d %s (boss %v) after %gs",
+				i+1, wave.Count(), group.Enemy, wave.Boss, wave.Delay, tt.count, tt.enemy, tt.boss, tt.delay)
+		}
+	}
+
+	script.Endless = nil
+	if script.Wave(1) != nil {
+		t.Error("a campaign with no generator kept going after its last wave")
+	}
+}
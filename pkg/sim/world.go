@@ -0,0 +1,437 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+)
+
+// ActionKind identifies a player action fed into the simulation
+type ActionKind int
+
+const (
+	ActionSelectTower ActionKind = iota
+	ActionPlaceTower
+	ActionRemoveTower
+	ActionBegin
+	ActionPause
+	ActionResume
+	ActionReset
+	ActionUpgradeTower
+	ActionSetTarget
+)
+
+// actionNames are the names actions are stored under in replay files
+var actionNames = map[ActionKind]string{
+	ActionSelectTower:  "select",
+	ActionPlaceTower:   "place",
+	ActionRemoveTower:  "remove",
+	ActionBegin:        "begin",
+	ActionPause:        "pause",
+	ActionResume:       "resume",
+	ActionReset:        "reset",
+	ActionUpgradeTower: "upgrade",
+	ActionSetTarget:    "target",
+}
+
+// MarshalText stores an action kind by name
+func (k ActionKind) MarshalText() ([]byte, error) {
+	name, ok := actionNames[k]
+	if !ok {
+		return nil, fmt.Errorf("unknown action %d", k)
+	}
+	return []byte(name), nil
+}
+
+// UnmarshalText reads an action kind stored by name
+func (k *ActionKind) UnmarshalText(text []byte) error {
+	for kind, name := range actionNames {
+		if name == string(text) {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown action %q", text)
+}
+
+// Action is a single player input applied at the start of a tick
+type Action struct {
+	Kind  ActionKind `json:"kind"`
+	Tower TowerType  `json:"tower,omitempty"` // Tower type for ActionSelectTower
+	X     int        `json:"x,omitempty"`     // Grid cell for the actions on a tower
+	Y     int        `json:"y,omitempty"`
+	// Target is the priority for ActionSetTarget
+	Target TargetPriority `json:"target,omitempty"`
+}
+
+// TickResult reports how the player actions of a tick were handled.
+// Gameplay effects are published on the world's EventBus instead.
+type TickResult struct {
+	Rejected []error // Actions that could not be applied, with the reason
+}
+
+// World holds the complete simulation state of a match
+type World struct {
+	Map            *GameMap
+	Layout         *MapFile // Map the match is played on; Reset rebuilds it
+	Enemies        []*Enemy
+	Projectiles    []*Projectile // Active projectiles
+	Score          int
+	Lives          int
+	Money          int // Points available for tower placement
+	State          GameState
+	Frame          int64 // Number of Tick calls so far, including build and pause time
+	Clock          int64 // Simulation time in ticks; only advances while a wave is in play
+	SpawnTimer     int
+	SpawnInterval  int
+	CurrentWave    int      // Index of the wave in play, counting from 0
+	Wave           *WaveDef // Definition of the current wave
+	EnemiesInWave  int
+	EnemiesSpawned int
+	LastEnemyID    int       // ID given to the last enemy spawned, counting from 1
+	WaveType       EnemyType // Enemy type whose colors the current wave wears
+	Victory        bool      // Set when the match ended because the campaign ran out of waves
+	SelectedTower  TowerType // Currently selected tower type
+	Seed           int64     // Seed the match was started with
+	Rand           *RNG      // Source of all gameplay randomness
+	Defs           *Defs     `json:"-"` // Tower, enemy and wave definitions the match is played with
+	Events         *EventBus `json:"-"` // Receives everything that happens during a tick
+}
+
+// NewWorld creates a new simulation in build mode on the default map. The
+// same seed and the same actions always produce the same match.
+func NewWorld(seed int64) *World {
+	layout, err := BuiltinMap(DefaultMap)
+	if err != nil {
+		panic(err)
+	}
+	return NewWorldOnMap(seed, layout)
+}
+
+// NewWorldOnMap creates a new simulation in build mode on the given map,
+// which should come from ParseMap, LoadMap or BuiltinMap. It is played with
+// the definitions loaded at the time.
+func NewWorldOnMap(seed int64, layout *MapFile) *World {
+	return newWorld(seed, layout, currentDefs())
+}
+
+// newWorld creates a new simulation in build mode on the given map, played
+// with the given definitions
+func newWorld(seed int64, layout *MapFile, defs *Defs) *World {
+	w := &World{
+		Map:           layout.Build(defs),
+		Layout:        layout,
+		Enemies:       make([]*Enemy, 0),
+		Projectiles:   make([]*Projectile, 0),
+		Score:         0,
+		Lives:         layout.StartingLives(),
+		Money:         layout.StartingMoney(),
+		State:         BuildState,
+		SelectedTower: DartTower, // Default to dart tower
+		Seed:          seed,
+		Rand:          NewRNG(seed),
+		Defs:          defs,
+		Events:        NewEventBus(),
+	}
+	w.setWave(0, defs.Waves.Wave(0))
+	return w
+}
+
+// TowerDefFor returns the definition of a tower type in the match, or nil
+// if it has none
+func (w *World) TowerDefFor(towerType TowerType) *TowerDef {
+	return w.Defs.tower(towerType)
+}
+
+// EnemyDefFor returns the definition of an enemy type in the match, or nil
+// if it has none
+func (w *World) EnemyDefFor(enemyType EnemyType) *EnemyDef {
+	return w.Defs.enemy(enemyType)
+}
+
+// Tick applies the given player actions and advances the simulation by one step
+func (w *World) Tick(actions []Action) TickResult {
+	var result TickResult
+
+	for _, action := range actions {
+		if err := w.apply(action); err != nil {
+			result.Rejected = append(result.Rejected, err)
+		}
+	}
+
+	// Update game logic only in play state
+	if w.State == PlayState {
+		w.step()
+	}
+
+	w.Frame++
+	return result
+}
+
+// apply performs a single player action through the matching command
+func (w *World) apply(action Action) error {
+	var err error
+	switch action.Kind {
+	case ActionSelectTower:
+		err = w.SelectTower(action.Tower)
+	case ActionPlaceTower:
+		_, err = w.PlaceTower(w.SelectedTower, action.X, action.Y)
+	case ActionRemoveTower:
+		_, err = w.SellTower(action.X, action.Y)
+	case ActionUpgradeTower:
+		_, err = w.UpgradeTower(action.X, action.Y)
+	case ActionSetTarget:
+		_, err = w.SetTargetPriority(action.X, action.Y, action.Target)
+	case ActionBegin:
+		err = w.StartWaves()
+	case ActionPause:
+		err = w.Pause()
+	case ActionResume:
+		err = w.Resume()
+	case ActionReset:
+		w.Reset()
+	default:
+		err = fmt.Errorf("unknown action %d", action.Kind)
+	}
+	return err
+}
+
+// reset returns the match to build mode with a fresh map
+func (w *World) reset() {
+	w.State = BuildState
+	w.Enemies = make([]*Enemy, 0)
+	w.Projectiles = make([]*Projectile, 0)
+	w.Lives = w.Layout.StartingLives()
+	w.Money = w.Layout.StartingMoney() // Reset to initial money amount
+	// Back to the first wave, even during a boss wave
+	w.setWave(0, w.Defs.Waves.Wave(0))
+	w.Victory = false
+	w.Clock = 0
+	w.Score = 0
+	w.LastEnemyID = 0
+	// Clear all towers from the map
+	w.Map = w.Layout.Build(w.Defs)
+	// Reset selected tower to default
+	w.SelectedTower = DartTower
+	// Restart the random stream so a reset replays like a fresh match
+	w.Rand = NewRNG(w.Seed)
+}
+
+// step advances enemies, towers, projectiles and waves by one tick
+func (w *World) step() {
+	// Update enemies
+	remainingEnemies := make([]*Enemy, 0, len(w.Enemies))
+	for _, enemy := range w.Enemies {
+		if enemy == nil {
+			continue
+		}
+
+		// An enemy burnt or shot down is killed before it can take another
+		// step, so one that dies on its last cell doesn't get away
+		w.burnEnemy(enemy)
+		if enemy.Health <= 0 {
+			// Award points per level, plus any bonus score (bosses)
+			def := w.EnemyDefFor(enemy.Type)
+			reward := def.Reward * enemy.Level
+			w.Score += def.KillScore
+			w.Money += reward
+			w.Events.Publish(Event{Kind: EventEnemyKilled, Enemy: enemy, Points: reward})
+		} else if enemy.Update(w) {
+			w.Lives--
+			w.Events.Publish(Event{Kind: EventEnemyLeaked, Enemy: enemy})
+			if w.Lives <= 0 && w.State != GameOverState {
+				w.State = GameOverState
+				w.Events.Publish(Event{Kind: EventGameOver, Wave: w.CurrentWave})
+			}
+		} else {
+			remainingEnemies = append(remainingEnemies, enemy)
+		}
+	}
+	w.Enemies = remainingEnemies
+
+	// Update towers and generate projectiles
+	for _, tower := range w.Map.Towers {
+		newProjectiles := tower.Update(w)
+		if newProjectiles != nil {
+			w.Projectiles = append(w.Projectiles, newProjectiles...)
+		}
+	}
+
+	// Update projectiles and check for hits
+	remainingProjectiles := make([]*Projectile, 0, len(w.Projectiles))
+	for _, proj := range w.Projectiles {
+		hit := proj.Update()
+		if !hit {
+			// Keep projectile if it hasn't hit
+			remainingProjectiles = append(remainingProjectiles, proj)
+		} else {
+			w.projectileHit(proj)
+		}
+	}
+	w.Projectiles = remainingProjectiles
+
+	w.updateWave()
+
+	w.Clock++
+}
+
+// projectileHit applies a projectile that reached its target point to the
+// enemy it landed on, if any, and to the enemies around it
+func (w *World) projectileHit(proj *Projectile) {
+	var hit *Enemy
+	for _, enemy := range w.Enemies {
+		// Only anti-air shots can hit flyers
+		if enemy == nil || enemy.CanFly && !proj.AntiAir {
+			continue
+		}
+
+		// Simple collision check
+		ex, ey := enemy.X, enemy.Y
+		px, py := proj.GetPosition()
+		dx := ex - px
+		dy := ey - py
+		dist := math.Sqrt(dx*dx + dy*dy)
+
+		if dist < enemy.Size/2 { // If within enemy radius
+			hit = enemy
+			break // Exit after first hit
+		}
+	}
+
+	if hit != nil {
+		if proj.GetDamage() > 0 { // Freeze shots only chill
+			w.damageEnemy(hit, proj.GetDamage(), proj.Source)
+		}
+		w.chillEnemy(hit, proj)
+		igniteEnemy(hit, proj)
+		if proj.Chain > 0 {
+			w.chainShot(proj, hit)
+		}
+	}
+
+	// Area shots burst where they land, even when they miss
+	if proj.Splash > 0 {
+		w.splashShot(proj, hit)
+	}
+}
+
+// damageEnemy hurts an enemy and credits the tower that did it, if any,
+// with the health taken off and with the kill if the enemy drops
+func (w *World) damageEnemy(enemy *Enemy, damage float64, source *Tower) {
+	if source != nil && enemy.Health > 0 {
+		source.DamageDealt += math.Min(damage, enemy.Health)
+		if damage >= enemy.Health {
+			source.Kills++
+		}
+	}
+	enemy.Health -= damage
+	w.Events.Publish(Event{Kind: EventEnemyDamaged, Enemy: enemy, Tower: source, Damage: damage})
+}
+
+// updateWave spawns the enemies of the current wave and moves on to the
+// next wave once the field is clear
+func (w *World) updateWave() {
+	if w.EnemiesSpawned < w.EnemiesInWave {
+		w.SpawnTimer++
+		if w.SpawnTimer >= w.SpawnInterval {
+			w.SpawnTimer = 0
+			group := w.Wave.group(w.EnemiesSpawned)
+			w.SpawnInterval = group.spacing(w.Rand)
+
+			// Sometimes spawn a different enemy type
+			spawnType := enemyIDs[group.Enemy]
+			if chance := w.Wave.Modifiers.OffTypeChance; chance > 0 && w.EnemiesSpawned > 0 {
+				if w.Rand.Float64() < chance {
+					// Create list of enemy types excluding the group's type and bosses
+					availableTypes := []EnemyType{}
+					for _, def := range w.Defs.Enemies {
+						if def != nil && !def.Boss && def.Type != spawnType {
+							availableTypes = append(availableTypes, def.Type)
+						}
+					}
+					if len(availableTypes) > 0 {
+						spawnType = availableTypes[w.Rand.Intn(len(availableTypes))]
+					}
+				}
+			}
+
+			level := group.Level
+			if level == 0 {
+				level = w.CurrentWave + 1
+			}
+
+			// Spawn new enemy with current wave's colors
+			route := w.pickRoute(group.Route)
+			x, y := w.spawnPoint(route)
+			newEnemy := NewEnemy(x, y, w.Map.CellSize, w.EnemyDefFor(spawnType), level, w.EnemyDefFor(w.WaveType))
+			if newEnemy != nil {
+				w.LastEnemyID++
+				newEnemy.ID = w.LastEnemyID
+				newEnemy.Route = route
+				if mod := w.Wave.Modifiers.Health; mod > 0 {
+					newEnemy.Health *= mod
+					newEnemy.MaxHealth *= mod
+				}
+				if mod := w.Wave.Modifiers.Speed; mod > 0 {
+					newEnemy.Speed *= mod
+				}
+				w.Enemies = append(w.Enemies, newEnemy)
+				w.EnemiesSpawned++
+				w.Events.Publish(Event{Kind: EventEnemySpawned, Enemy: newEnemy, Wave: w.CurrentWave})
+			}
+		}
+
+	} else if len(w.Enemies) == 0 {
+		// Wave completed
+		w.Events.Publish(Event{Kind: EventWaveEnded, Wave: w.CurrentWave})
+
+		next := w.Defs.Waves.Wave(w.CurrentWave + 1)
+		if next == nil {
+			// The campaign is over and the player survived it
+			w.Victory = true
+			w.State = GameOverState
+			w.Events.Publish(Event{Kind: EventGameOver, Wave: w.CurrentWave})
+			return
+		}
+		w.setWave(w.CurrentWave+1, next)
+		w.Events.Publish(Event{Kind: EventWaveStarted, Wave: w.CurrentWave})
+	}
+}
+
+// pickRoute picks the route the next enemy follows: the given route
+// (counting from 1), or a random one for 0. It returns an index into the
+// map's routes.
+func (w *World) pickRoute(route int) int {
+	routes := w.Map.Routes
+	switch {
+	case route > 0 && route <= len(routes):
+		return route - 1
+	case len(routes) == 1:
+		return 0
+	}
+	return w.Rand.Intn(len(routes))
+}
+
+// spawnPoint picks a random cell of a route's entrance for the next enemy
+// to appear at
+func (w *World) spawnPoint(route int) (float64, float64) {
+	span := w.Map.RouteEntrance(route)
+	cells := w.Map.SpanCells(span)
+	return w.Map.SpawnPoint(span, cells[w.Rand.Intn(len(cells))])
+}
+
+// setWave makes the given wave the one in play
+func (w *World) setWave(index int, wave *WaveDef) {
+	w.CurrentWave = index
+	w.Wave = wave
+	w.EnemiesInWave = wave.Count()
+	w.EnemiesSpawned = 0
+	w.WaveType = wave.Lead()
+	w.SpawnTimer = 0
+	w.SpawnInterval = secondsToTicks(wave.Delay)
+}
+
+// NextWaveIsBoss reports whether the wave after the current one is a boss wave
+func (w *World) NextWaveIsBoss() bool {
+	next := w.Defs.Waves.Wave(w.CurrentWave + 1)
+	return next != nil && next.Boss
+}
@@ -0,0 +1,99 @@
+package sim
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// playSeed plays a fixed opening on a fresh world for a while and describes
+// where the match ended up
+func playSeed(seed int64) string {
+	w := NewWorld(seed)
+	w.Tick([]Action{
+		{Kind: ActionSelectTower, Tower: BulletTower},
+		{Kind: ActionPlaceTower, X: 5, Y: 5},
+		{Kind: ActionPlaceTower, X: 8, Y: 7},
+		{Kind: ActionBegin},
+	})
+	for range 3000 {
+		w.Tick(nil)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "clock %d wave %d lives %d money %d score %d rng %d\n", w.Clock, w.CurrentWave, w.Lives, w.Money, w.Score, w.Rand.State)
+	for _, e := range w.Enemies {
+		fmt.Fprintf(&b, "%d at %g,%g health %g\n", e.Type, e.X, e.Y, e.Health)
+	}
+	return b.String()
+}
+
+func TestSameSeedSameMatch(t *testing.T) {
+	first := playSeed(7)
+	if again := playSeed(7); again != first {
+		t.Errorf("same seed played out differently:\n%s\nthen\n%s", first, again)
+	}
+	if other := playSeed(8); other == first {
+		t.Error("a different seed played out exactly the same")
+	}
+}
+
+func TestSpawnRoutes(t *testing.T) {
+	layout, err := BuiltinMap("gates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewWorldOnMap(3, layout)
+	w.setWave(0, &WaveDef{Groups: []WaveGroup{
+		{Enemy: "spider", Count: 3, Route: 2, MinSpacing: 0.5, MaxSpacing: 0.5},
+		{Enemy: "spider", Count: 3, Route: 1, MinSpacing: 0.5, MaxSpacing: 0.5},
+		{Enemy: "spider", Count: 12, MinSpacing: 0.5, MaxSpacing: 0.5},
+	}})
+
+	// Enemies appear on their route's entrance and leave by its exit
+	var routes []int
+	leaked := 0
+	w.Events.Subscribe(EventEnemySpawned, func(e Event) {
+		if len(routes) == 18 {
+			return // The next wave
+		}
+		routes = append(routes, e.Enemy.Route)
+		x, y := w.Map.CellAt(e.Enemy.X, e.Enemy.Y)
+		if !w.Map.inSpans([]Span{w.Map.RouteEntrance(e.Enemy.Route)}, x, y) {
+			t.Errorf("enemy on route %d appeared at %d,%d", e.Enemy.Route+1, x, y)
+		}
+	})
+	w.Events.Subscribe(EventEnemyLeaked, func(e Event) {
+		leaked++
+		x, y := w.Map.CellAt(e.Enemy.X, e.Enemy.Y)
+		if !w.Map.IsRouteExit(e.Enemy.Route, x, y) {
+			t.Errorf("enemy on route %d left at %d,%d", e.Enemy.Route+1, x, y)
+		}
+	})
+	if err := w.StartWaves(); err != nil {
+		t.Fatal(err)
+	}
+	for range 60 * TicksPerSecond {
+		if leaked >= 18 {
+			break
+		}
+		w.Tick(nil)
+	}
+	if leaked < 18 {
+		t.Fatalf("only %d of 18 enemies got out", leaked)
+	}
+
+	// Groups keep to the route they're given, and the rest are spread
+	// across every route
+	if got := routes[:6]; !slices.Equal(got, []int{1, 1, 1, 0, 0, 0}) {
+		t.Errorf("groups with a route spawned on %v", got)
+	}
+	picked := map[int]bool{}
+	for _, route := range routes[6:] {
+		picked[route] = true
+	}
+	if !picked[0] || !picked[1] {
+		t.Errorf("random spawns only took routes %v", picked)
+	}
+}